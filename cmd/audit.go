@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"defenders-cli/internal/utils"
+)
+
+// auditAction records an action in the local audit log. A failure to write the
+// log is reported but does not undo or fail the action that was already taken.
+// A nil identity, when it could not be resolved, is logged as unknown.
+func auditAction(identity *utils.Identity, delegated bool, target, action string) {
+	if identity == nil {
+		identity = &utils.Identity{DisplayName: "unknown"}
+	}
+	err := utils.WriteAuditLog(utils.AuditEntry{
+		Identity:  identity.DisplayName,
+		Email:     identity.Email,
+		Delegated: delegated,
+		Target:    target,
+		Action:    action,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not write audit log: %s\n", err)
+	}
+}
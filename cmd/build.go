@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+// buildRun holds the fields of an Azure DevOps build (pipeline run) that defenders commands use
+type buildRun struct {
+	ID            int    `json:"id"`
+	BuildNumber   string `json:"buildNumber"`
+	Status        string `json:"status"`
+	Result        string `json:"result"`
+	SourceBranch  string `json:"sourceBranch"`
+	SourceVersion string `json:"sourceVersion"`
+	Definition    struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"definition"`
+	// TemplateParameters are the runtime parameters the run was queued with
+	TemplateParameters map[string]interface{} `json:"templateParameters"`
+	// Parameters is a JSON object of the variables set at queue time
+	Parameters string `json:"parameters"`
+}
+
+// buildWebURL returns the browser URL of a build
+func buildWebURL(orgURL, project string, buildID int) string {
+	return fmt.Sprintf("%s/%s/_build/results?buildId=%d&view=results", orgURL, project, buildID)
+}
+
+// getBuild fetches a build by ID
+func getBuild(pat, orgURL, project, buildID string) (*buildRun, error) {
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "pipelines", "runs", "show",
+		"--id", buildID,
+		"--org", orgURL,
+		"--project", project,
+		"-o", "json",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not get build %s: %s", buildID, strings.TrimSpace(stderr))
+	}
+
+	var build buildRun
+	if err := json.Unmarshal([]byte(stdout), &build); err != nil {
+		return nil, fmt.Errorf("could not parse build: %w", err)
+	}
+	return &build, nil
+}
+
+// parseBuildURL extracts org, project and build ID from a pipeline run URL
+func parseBuildURL(rawURL string) (orgURL, project string, buildID int, err error) {
+	orgURL, project, queryParams, err := parseADOUrl(rawURL)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	buildID, err = strconv.Atoi(queryParams.Get("buildId"))
+	if err != nil {
+		return "", "", 0, fmt.Errorf("could not extract buildId from URL")
+	}
+	return orgURL, project, buildID, nil
+}
+
+// parseDefinitionURL extracts org, project and definition ID from a pipeline definition URL
+func parseDefinitionURL(rawURL string) (orgURL, project, definitionID string, err error) {
+	orgURL, project, queryParams, err := parseADOUrl(rawURL)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	definitionID = queryParams.Get("definitionId")
+	if definitionID == "" {
+		return "", "", "", fmt.Errorf("could not extract definitionId from URL")
+	}
+	return orgURL, project, definitionID, nil
+}
+
+// runOptions are the optional settings of a pipeline run
+type runOptions struct {
+	Branch       string
+	Commit       string
+	Parameters   map[string]string
+	Variables    map[string]string
+	StagesToSkip []string
+}
+
+// triggerPipeline queues a new run of a pipeline definition
+func triggerPipeline(pat, orgURL, project, definitionID string, opts runOptions) (*buildRun, error) {
+	// Skipping stages is only supported by the Pipelines Runs REST API
+	if len(opts.StagesToSkip) > 0 {
+		return runPipelineREST(pat, orgURL, project, definitionID, opts)
+	}
+
+	args := []string{"pipelines", "run",
+		"--id", definitionID,
+		"--org", orgURL,
+		"--project", project,
+		"-o", "json",
+	}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.Commit != "" {
+		args = append(args, "--commit-id", opts.Commit)
+	}
+	if len(opts.Parameters) > 0 {
+		args = append(args, "--parameters")
+		args = append(args, keyValuePairs(opts.Parameters)...)
+	}
+	if len(opts.Variables) > 0 {
+		args = append(args, "--variables")
+		args = append(args, keyValuePairs(opts.Variables)...)
+	}
+
+	// Run pipeline using az CLI (with PAT if provided, otherwise az login)
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(stderr))
+	}
+
+	var build buildRun
+	if err := json.Unmarshal([]byte(stdout), &build); err != nil {
+		return nil, fmt.Errorf("could not parse response: %w", err)
+	}
+	return &build, nil
+}
+
+// runPipelineREST queues a run through the Pipelines Runs API (YAML pipelines only)
+func runPipelineREST(pat, orgURL, project, definitionID string, opts runOptions) (*buildRun, error) {
+	body := map[string]interface{}{
+		"stagesToSkip": opts.StagesToSkip,
+	}
+
+	if opts.Branch != "" || opts.Commit != "" {
+		self := map[string]string{}
+		if opts.Branch != "" {
+			self["refName"] = opts.Branch
+			if !strings.HasPrefix(opts.Branch, "refs/") {
+				self["refName"] = "refs/heads/" + opts.Branch
+			}
+		}
+		if opts.Commit != "" {
+			self["version"] = opts.Commit
+		}
+		body["resources"] = map[string]interface{}{
+			"repositories": map[string]interface{}{"self": self},
+		}
+	}
+	if len(opts.Parameters) > 0 {
+		body["templateParameters"] = opts.Parameters
+	}
+	if len(opts.Variables) > 0 {
+		variables := map[string]interface{}{}
+		for k, v := range opts.Variables {
+			variables[k] = map[string]string{"value": v}
+		}
+		body["variables"] = variables
+	}
+
+	var run struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		State string `json:"state"`
+	}
+	reqURL := fmt.Sprintf("%s/%s/_apis/pipelines/%s/runs?api-version=7.1",
+		orgURL, url.PathEscape(project), definitionID)
+	if err := utils.ADORequest(pat, "POST", reqURL, body, &run); err != nil {
+		return nil, err
+	}
+
+	// A pipeline run is a build, with the same ID
+	return &buildRun{ID: run.ID, BuildNumber: run.Name, Status: run.State}, nil
+}
+
+// keyValuePairs returns "key=value" arguments sorted by key
+func keyValuePairs(values map[string]string) []string {
+	pairs := make([]string, 0, len(values))
+	for k, v := range values {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// timelineRecord is a stage, job or task of a build timeline
+type timelineRecord struct {
+	ID         string     `json:"id"`
+	ParentID   string     `json:"parentId"`
+	Type       string     `json:"type"`
+	Name       string     `json:"name"`
+	Identifier string     `json:"identifier"`
+	State      string     `json:"state"`
+	Result     string     `json:"result"`
+	Order      int        `json:"order"`
+	StartTime  *time.Time `json:"startTime"`
+	FinishTime *time.Time `json:"finishTime"`
+	Log        *struct {
+		ID int `json:"id"`
+	} `json:"log"`
+	Issues []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"issues"`
+}
+
+// duration returns how long the record ran, or ran so far when it is in progress
+func (r *timelineRecord) duration() time.Duration {
+	if r.StartTime == nil {
+		return 0
+	}
+	end := time.Now()
+	if r.FinishTime != nil {
+		end = *r.FinishTime
+	}
+	return end.Sub(*r.StartTime).Round(time.Second)
+}
+
+// getTimeline returns the timeline records (stages, jobs, tasks) of a build
+func getTimeline(pat, orgURL, project string, buildID int) ([]timelineRecord, error) {
+	var data struct {
+		Records []timelineRecord `json:"records"`
+	}
+	reqURL := fmt.Sprintf("%s/%s/_apis/build/builds/%d/timeline?api-version=7.1", orgURL, url.PathEscape(project), buildID)
+	if err := utils.ADORequest(pat, "GET", reqURL, nil, &data); err != nil {
+		return nil, err
+	}
+	return data.Records, nil
+}
+
+// errBuildTimeout is returned by waitForBuild when the timeout is reached
+var errBuildTimeout = errors.New("timed out waiting for the build")
+
+// errBuildUnreachable is returned by waitForBuild when the build status could
+// not be checked maxRetries times in a row, e.g. with a revoked PAT
+var errBuildUnreachable = errors.New("could not check the build status")
+
+// waitForBuild polls a build until it completes, printing stage and job progress
+// as it changes. Failed status checks are retried after retryDelay, up to
+// maxRetries in a row. A timeout of 0 waits forever.
+func waitForBuild(pat, orgURL, project string, buildID, interval int, timeout time.Duration, maxRetries int) (*buildRun, error) {
+	deadline := time.Now().Add(timeout)
+	lastStatus := ""
+	lastWaiting := ""
+	seen := make(map[string]string)
+	failures := 0
+
+	for {
+		delay := time.Duration(interval) * time.Second
+		build, err := getBuild(pat, orgURL, project, strconv.Itoa(buildID))
+		if err != nil {
+			failures++
+			if failures > maxRetries {
+				return nil, fmt.Errorf("%w after %d failed checks in a row: %s", errBuildUnreachable, maxRetries, err)
+			}
+			delay = retryDelay(failures)
+			fmt.Fprintf(os.Stderr, "Error checking build status (%d/%d), retrying in %s: %s\n", failures, maxRetries, delay, err)
+		} else {
+			failures = 0
+
+			now := time.Now().Format("15:04:05")
+
+			status := build.Status
+			if build.Result != "" {
+				status += " (result: " + build.Result + ")"
+			}
+			if status != lastStatus {
+				fmt.Printf("[%s] Build %s: %s\n", now, build.BuildNumber, status)
+				lastStatus = status
+			}
+
+			// Progress is best effort, the timeline may not exist yet
+			if records, err := getTimeline(pat, orgURL, project, buildID); err == nil {
+				printTimelineChanges(records, seen, now)
+
+				waiting := ""
+				if checks, err := pendingChecks(pat, orgURL, project, records); err == nil && len(checks) > 0 {
+					waiting = describeChecks(checks)
+				}
+				if waiting != "" && waiting != lastWaiting {
+					fmt.Printf("[%s] Build %s is %s\n", now, build.BuildNumber, waiting)
+				}
+				lastWaiting = waiting
+			}
+
+			if build.Status == "completed" {
+				return build, nil
+			}
+		}
+
+		if timeout > 0 && time.Now().Add(delay).After(deadline) {
+			return build, errBuildTimeout
+		}
+		time.Sleep(delay)
+	}
+}
+
+// printTimelineChanges prints stages and jobs whose state changed since the last
+// call. seen maps record IDs to the state they were last printed with.
+func printTimelineChanges(records []timelineRecord, seen map[string]string, now string) {
+	for _, r := range records {
+		if r.Type != "Stage" && r.Type != "Job" {
+			continue
+		}
+		state := r.State
+		if r.Result != "" {
+			state = r.Result
+		}
+		if state == "" || seen[r.ID] == state || (seen[r.ID] == "" && state == "pending") {
+			continue
+		}
+		seen[r.ID] = state
+
+		indent := ""
+		if r.Type == "Job" {
+			indent = "  "
+		}
+		fmt.Printf("[%s]   %s%s %s: %s\n", now, indent, r.Type, r.Name, state)
+	}
+}
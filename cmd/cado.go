@@ -35,7 +35,7 @@ type CadoCmd struct {
 
 func (c *CadoCmd) Run() {
 	if c.Title == "" {
-		fmt.Println(cadoHelp)
+		fmt.Print(cadoHelp)
 		os.Exit(1)
 	}
 
@@ -132,7 +132,7 @@ func ParseCadoArgs(args []string) *CadoCmd {
 		case strings.HasPrefix(arg, "--assigned-to="):
 			cmd.AssignedTo = strings.TrimPrefix(arg, "--assigned-to=")
 		case arg == "-h" || arg == "--help":
-			fmt.Println(cadoHelp)
+			fmt.Print(cadoHelp)
 			os.Exit(0)
 		}
 	}
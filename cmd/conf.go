@@ -47,7 +47,7 @@ func (c *ConfCmd) Run() {
 		c.interactiveSetup()
 	default:
 		fmt.Printf("Unknown subcommand: %s\n", c.Subcommand)
-		fmt.Println(confHelp)
+		fmt.Print(confHelp)
 		os.Exit(1)
 	}
 }
@@ -278,7 +278,7 @@ func ParseConfArgs(args []string) *ConfCmd {
 	for _, arg := range args {
 		switch {
 		case arg == "-h" || arg == "--help":
-			fmt.Println(confHelp)
+			fmt.Print(confHelp)
 			os.Exit(0)
 		default:
 			if !strings.HasPrefix(arg, "-") && cmd.Subcommand == "" {
@@ -94,7 +94,7 @@ func openBrowser(url string) error {
 func ParseGetTokenArgs(args []string) *GetTokenCmd {
 	for _, arg := range args {
 		if arg == "-h" || arg == "--help" {
-			fmt.Println(gettokenHelp)
+			fmt.Print(gettokenHelp)
 			os.Exit(0)
 		}
 	}
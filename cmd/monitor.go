@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+const monitorStatusHelp = `release monitor-status - Show monitors started with --detach
+
+USAGE:
+  defenders release monitor-status [<id>]
+
+Without an ID, all detached monitors are listed. With an ID, the monitored
+builds, triggered runs and the log file of that monitor are shown.
+
+EXAMPLES:
+  defenders release monitor-trigger <wait-url> <trigger-url> --detach
+  defenders release monitor-status
+  defenders release monitor-status 20241018-153012-48213
+`
+
+// monitorStateEnv holds the state file of a detached monitor process
+const monitorStateEnv = "DEFENDERS_MONITOR_STATE"
+
+// monitorPATEnv passes the -t PAT to a detached monitor process, so that it is
+// neither saved in the state file nor visible in the process arguments
+const monitorPATEnv = "DEFENDERS_MONITOR_PAT"
+
+// monitorState is the state of a detached monitor, written after every check
+type monitorState struct {
+	ID        string               `json:"id"`
+	PID       int                  `json:"pid,omitempty"`
+	Args      []string             `json:"args"`
+	Interval  int                  `json:"interval"`
+	LogFile   string               `json:"logFile"`
+	StartedAt time.Time            `json:"startedAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+	Status    string               `json:"status"` // running, triggered, failed, timed out, interrupted or error
+	Message   string               `json:"message,omitempty"`
+	Builds    []monitorBuildStatus `json:"builds"`
+	Triggered []string             `json:"triggered,omitempty"`
+}
+
+// monitorBuildStatus is the last known state of a monitored build
+type monitorBuildStatus struct {
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	Result string `json:"result,omitempty"`
+}
+
+// getMonitorDir returns the directory holding detached monitor state and logs
+func getMonitorDir() (string, error) {
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "monitors"), nil
+}
+
+// retryDelay returns the wait before retry n (1-based) after failed status
+// checks: 5s, 10s, 20s, ... at most 5 minutes
+func retryDelay(n int) time.Duration {
+	delay := 5 * time.Second
+	for i := 1; i < n && delay < 5*time.Minute; i++ {
+		delay *= 2
+	}
+	if delay > 5*time.Minute {
+		delay = 5 * time.Minute
+	}
+	return delay
+}
+
+// printWatchedBuilds prints the last known state of the monitored builds
+func printWatchedBuilds(waits []*watchedBuild) {
+	fmt.Println("Last known state:")
+	for _, w := range waits {
+		status, result := "unknown", ""
+		if w.build != nil {
+			status, result = w.build.Status, w.build.Result
+		}
+		if result != "" {
+			status += " (result: " + result + ")"
+		}
+		fmt.Printf("  Pipeline %s: %s\n", w.buildID, status)
+		fmt.Printf("      %s\n", w.url)
+	}
+}
+
+// saveMonitorState updates the state file of a detached monitor. It does nothing
+// when the monitor runs in the foreground.
+func saveMonitorState(waits []*watchedBuild, status, message string, triggered []string) {
+	statePath := os.Getenv(monitorStateEnv)
+	if statePath == "" {
+		return
+	}
+
+	state, err := readMonitorState(statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+		return
+	}
+
+	state.PID = os.Getpid()
+	state.UpdatedAt = time.Now()
+	state.Status = status
+	state.Message = message
+	state.Builds = nil
+	for _, w := range waits {
+		b := monitorBuildStatus{URL: w.url, Status: "unknown"}
+		if w.build != nil {
+			b.Status, b.Result = w.build.Status, w.build.Result
+		}
+		state.Builds = append(state.Builds, b)
+	}
+	if triggered != nil {
+		state.Triggered = triggered
+	}
+
+	if err := writeMonitorState(statePath, state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+	}
+}
+
+// readMonitorState reads the state file of a detached monitor
+func readMonitorState(path string) (*monitorState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read monitor state: %w", err)
+	}
+	var state monitorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("could not parse monitor state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// writeMonitorState replaces the state file of a detached monitor atomically
+func writeMonitorState(path string, state *monitorState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialize monitor state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("could not write monitor state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not write monitor state: %w", err)
+	}
+	return nil
+}
+
+// detachMonitor starts the same monitor-trigger command in a background process
+// that writes its output to a log file and its progress to a state file
+func (p *PiperunCmd) detachMonitor(interval int) {
+	monitorDir, err := getMonitorDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(monitorDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not create monitor directory: %s\n", err)
+		os.Exit(1)
+	}
+
+	// The PID keeps monitors detached in the same second apart
+	id := fmt.Sprintf("%s-%d", time.Now().Format("20060102-150405"), os.Getpid())
+	statePath := filepath.Join(monitorDir, id+".json")
+	logPath := filepath.Join(monitorDir, id+".log")
+
+	// The background process cannot prompt, the identity was confirmed already.
+	// The PAT goes through the environment, see monitorPATEnv.
+	var args []string
+	for _, arg := range stripTokenArgs(os.Args[1:]) {
+		if arg != "--detach" {
+			args = append(args, arg)
+		}
+	}
+	args = append(args, "--force")
+
+	state := &monitorState{
+		ID:        id,
+		Args:      args,
+		Interval:  interval,
+		LogFile:   logPath,
+		StartedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Status:    "running",
+	}
+	if err := writeMonitorState(statePath, state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not create log file: %s\n", err)
+		os.Exit(1)
+	}
+	defer logFile.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	monitor := exec.Command(exe, args...)
+	monitor.Stdout = logFile
+	monitor.Stderr = logFile
+	monitor.Env = append(os.Environ(), monitorStateEnv+"="+statePath)
+	if p.PAT != "" {
+		monitor.Env = append(monitor.Env, monitorPATEnv+"="+p.PAT)
+	}
+	if err := monitor.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not start background monitor: %s\n", err)
+		os.Exit(1)
+	}
+	monitor.Process.Release()
+
+	fmt.Printf("✓ Monitor %s running in the background\n", id)
+	fmt.Printf("  Log:    %s\n", logPath)
+	fmt.Printf("  Status: defenders release monitor-status %s\n", id)
+}
+
+func (p *PiperunCmd) monitorStatus() {
+	monitorDir, err := getMonitorDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if p.MonitorID != "" {
+		state, err := readMonitorState(filepath.Join(monitorDir, p.MonitorID+".json"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		printMonitorState(state, true)
+		return
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(monitorDir, "*.json"))
+	if len(paths) == 0 {
+		fmt.Println("No detached monitors.")
+		return
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		state, err := readMonitorState(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+			continue
+		}
+		printMonitorState(state, false)
+	}
+}
+
+// printMonitorState prints a detached monitor, with its builds when detailed
+func printMonitorState(state *monitorState, detailed bool) {
+	status := state.Status
+	// A running monitor that stopped updating was killed or the machine slept
+	if status == "running" && time.Since(state.UpdatedAt) > 3*time.Duration(state.Interval)*time.Second+10*time.Minute {
+		status = "running? (no update for " + formatAge(state.UpdatedAt) + ")"
+	}
+	fmt.Printf("%s  %-12s  started %s ago, updated %s ago\n", state.ID, status,
+		formatAge(state.StartedAt), formatAge(state.UpdatedAt))
+	if state.Message != "" {
+		fmt.Printf("    %s\n", state.Message)
+	}
+
+	if !detailed {
+		return
+	}
+
+	fmt.Printf("    Command: defenders %s\n", strings.Join(redactTokenArgs(state.Args), " "))
+	fmt.Println("    Monitored:")
+	for _, b := range state.Builds {
+		line := b.Status
+		if b.Result != "" {
+			line += " (result: " + b.Result + ")"
+		}
+		fmt.Printf("      %s: %s\n", b.URL, line)
+	}
+	if len(state.Triggered) > 0 {
+		fmt.Println("    Triggered:")
+		for _, url := range state.Triggered {
+			fmt.Printf("      %s\n", url)
+		}
+	}
+	fmt.Printf("    Log: %s\n", state.LogFile)
+}
+
+// stripTokenArgs returns args without -t/--token and its value
+func stripTokenArgs(args []string) []string {
+	var kept []string
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-t" || arg == "--token":
+			i++
+		case strings.HasPrefix(arg, "-t=") || strings.HasPrefix(arg, "--token="):
+		default:
+			kept = append(kept, arg)
+		}
+	}
+	return kept
+}
+
+// redactTokenArgs returns args with the value of -t/--token masked, for state
+// files written before the PAT was kept out of them
+func redactTokenArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case (arg == "-t" || arg == "--token") && i+1 < len(args):
+			redacted[i] = arg
+			i++
+			redacted[i] = "***"
+		case strings.HasPrefix(arg, "-t="):
+			redacted[i] = "-t=***"
+		case strings.HasPrefix(arg, "--token="):
+			redacted[i] = "--token=***"
+		default:
+			redacted[i] = arg
+		}
+	}
+	return redacted
+}
@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"defenders-cli/internal/utils"
@@ -20,69 +23,211 @@ USAGE:
 SUBCOMMANDS:
   run              Run a pipeline directly
   monitor-trigger  Monitor a pipeline and trigger another when it completes
+  monitor-status   Show monitors running in the background (--detach)
+  logs             Show the timeline of a run and the logs of failed tasks
+  why              Summarize why a run failed (errors, log tails, failing tests)
+  artifacts        List and download the artifacts of a run
+  cancel           Cancel a run
+  retry            Rerun the failed jobs of a run (or of one stage)
+  rerun            Start a new run with the branch, commit and parameters of a run
+  approve          Approve or reject the pending environment approvals of a run
+  plan run         Run a chain or graph of pipelines described in a YAML plan
+  list             List pipeline definitions (--name, --folder)
+  runs             List recent runs of a pipeline (--branch, --result, --top)
 
 FLAGS:
   -t, --token      Personal Access Token (overrides config/env)
   -i, --interval   Check interval in seconds (default: 30, for monitor-trigger)
+  -f, --force      Do not ask for confirmation when using another user's PAT
   -h, --help       Show this help message
 
 EXAMPLES:
   defenders release run <pipeline-definition-url>
   defenders release run <pipeline-definition-url> -t <token>
+  defenders release run <pipeline-definition-url> -b main -p environment=prod
   defenders release monitor-trigger <wait-for-build-url> <trigger-pipeline-url>
   defenders release monitor-trigger <wait-url> <trigger-url> --interval 60
+  defenders release plan run release.yaml
+  defenders release logs <wait-for-build-url> --follow
 
 URL FORMATS:
   <wait-for-build-url>:      https://dev.azure.com/org/proj/_build/results?buildId=123
   <pipeline-definition-url>: https://dev.azure.com/org/proj/_build?definitionId=456
 
+  Instead of a definition URL, a pipeline ID (456) or name ("Deploy Service",
+  or Release\Deploy with its folder) of the configured org and project works.
+
 AUTHENTICATION:
   PAT with 'Build (Read & Execute)' permissions required.
+  With another user's PAT (-t), the identity behind it is shown and must be
+  confirmed. Pipeline runs are recorded in the audit log next to the config file.
   Create at: https://msazure.visualstudio.com/_usersSettings/tokens
 `
 
 const piperunRunHelp = `release run - Run an Azure DevOps pipeline
 
 USAGE:
-  defenders release run <pipeline-url> [-t <token>]
+  defenders release run <pipeline-url> [flags]
 
 ARGUMENTS:
-  <pipeline-url>  URL of the pipeline definition to run
+  <pipeline-url>  URL of the pipeline definition to run. Extra query parameters
+                  (e.g. &env=prod) are passed as pipeline parameters, and
+                  &branch=<name> selects the branch. A pipeline ID or name of
+                  the configured org and project works too
 
 FLAGS:
-  -t, --token     Personal Access Token (overrides config/env)
+  -b, --branch <name>         Branch to run (default: the pipeline's default branch)
+  --commit <sha>              Commit to run
+  -p, --parameter <key=value> Template parameter (repeatable)
+  -v, --variable <key=value>  Pipeline variable (repeatable, must be settable at queue time)
+  --params-file <path>        JSON object with template parameters
+  --stages-to-skip <a,b>      Stages to skip (YAML pipelines only)
+  -w, --wait                  Wait for the run to finish, showing stage and job progress
+  --timeout <duration>        Stop waiting after this long, e.g. 30m or 2h (with --wait)
+  --max-retries <n>           Failed status checks in a row before giving up (default: 5)
+  -i, --interval <seconds>    Check interval when waiting (default: 30)
+  -t, --token                 Personal Access Token (overrides config/env)
+  -f, --force                 Do not ask for confirmation when using another user's PAT
+
+Parameters from --parameter override --params-file, which overrides the URL.
+
+EXIT CODES (with --wait):
+  0  Succeeded
+  1  Error
+  2  Failed
+  3  Partially succeeded
+  4  Canceled
+  5  Timed out (the run is not canceled)
+  6  The status could not be checked --max-retries times in a row
 
-EXAMPLE:
+EXAMPLES:
   defenders release run https://dev.azure.com/org/project/_build?definitionId=456
+  defenders release run <url> -b release/2024.10 -p environment=prod -v dryRun=true
+  defenders release run <url> --params-file params.json --stages-to-skip Test,Canary
+  defenders release run <url> --wait --timeout 2h
 `
 
-const piperunMonitorHelp = `release monitor-trigger - Monitor a pipeline and trigger another when complete
+const piperunMonitorHelp = `release monitor-trigger - Monitor pipelines and trigger others when complete
 
 USAGE:
-  defenders release monitor-trigger <wait-for-url> <trigger-url> [flags]
+  defenders release monitor-trigger <wait-for-url>... <trigger-url>... [flags]
 
 ARGUMENTS:
-  <wait-for-url>  URL of the pipeline run to wait for (buildId URL)
-  <trigger-url>   URL of the pipeline definition to trigger
+  <wait-for-url>  URL of a pipeline run to wait for (buildId URL)
+  <trigger-url>   URL of a pipeline definition to trigger (definitionId URL),
+                  or a pipeline ID or name of the configured org and project
+
+Several runs can be waited for and several pipelines triggered; URLs are told
+apart by their buildId or definitionId. The pipelines are triggered
+concurrently once all monitored runs succeeded (or one of them, with --any).
+Exits with 1 without triggering when that can no longer happen.
+
+Runs waiting for an environment approval or check are reported with their
+approvers, see 'release approve'.
+
+Failed status checks are retried after 5s, 10s, 20s, ... (at most 5 minutes).
+Ctrl-C stops monitoring and prints the last known state of the runs.
 
 FLAGS:
-  -t, --token      Personal Access Token (overrides config/env)
-  -i, --interval   Check interval in seconds (default: 30)
+  --any                 Trigger as soon as one monitored run succeeded
+  --all                 Trigger once all monitored runs succeeded (default)
+  --timeout <duration>  Give up after this long, e.g. 30m or 2h (exit code 5)
+  --max-retries <n>     Failed status checks in a row before giving up (default: 5)
+  --detach              Keep monitoring in a background process, see 'monitor-status'
+  -t, --token           Personal Access Token (overrides config/env)
+  -i, --interval        Check interval in seconds (default: 30)
+  -f, --force           Do not ask for confirmation when using another user's PAT
 
-EXAMPLE:
+EXAMPLES:
   defenders release monitor-trigger \
     https://dev.azure.com/org/proj/_build/results?buildId=123 \
     https://dev.azure.com/org/proj/_build?definitionId=456 \
     --interval 60
+
+  # Monitor in the background for at most 3 hours
+  defenders release monitor-trigger <wait-url> <trigger-url> --timeout 3h --detach
+
+  # When backend and frontend builds succeed, deploy to three regions
+  defenders release monitor-trigger <backend-build-url> <frontend-build-url> \
+    <deploy-eu-url> <deploy-us-url> <deploy-asia-url>
 `
 
 type PiperunCmd struct {
 	Subcommand  string
 	PipelineURL string
-	WaitForURL  string
-	TriggerURL  string
+	WaitForURLs []string
+	TriggerURLs []string
+	WaitAny     bool
+	MaxRetries  int
+	Detach      bool
+	MonitorID   string
 	PAT         string
 	Interval    int
+
+	// Run options for 'release run'
+	Branch       string
+	Commit       string
+	Parameters   []string
+	Variables    []string
+	ParamsFile   string
+	StagesToSkip []string
+	Wait         bool
+	Timeout      string
+
+	// Flags for 'release logs'
+	Task   string
+	Follow bool
+
+	// Flags for 'release why'
+	Format string
+	Lines  int
+	Output string
+
+	// Flags for 'release artifacts'
+	Download string
+	To       string
+	SHA256   string
+
+	// Flags for 'release retry' and 'release approve'
+	Stage   string
+	Comment string
+	Reject  bool
+
+	// Flags for 'release list' and 'release runs'
+	NamePattern string
+	Folder      string
+	Result      string
+	Top         int
+
+	// 'release plan run <plan.yaml>'
+	PlanAction string
+	PlanFile   string
+	Restart    bool
+}
+
+// Exit codes of 'release run --wait'
+const (
+	runExitSucceeded = 0
+	runExitFailed    = 2
+	runExitPartial   = 3
+	runExitCanceled  = 4
+	runExitTimeout   = 5
+	runExitNoStatus  = 6 // the status could not be checked --max-retries times in a row
+)
+
+// runExitCodes maps build results to the exit code of 'release run --wait'
+var runExitCodes = map[string]int{
+	"succeeded":          runExitSucceeded,
+	"partiallySucceeded": runExitPartial,
+	"failed":             runExitFailed,
+	"canceled":           runExitCanceled,
+}
+
+// urlQueryKeys are definition URL query parameters that are not pipeline parameters
+var urlQueryKeys = map[string]bool{
+	"definitionId": true,
+	"branch":       true,
+	"view":         true,
 }
 
 // parseADOUrl parses Azure DevOps URL and extracts org, project, and query params
@@ -91,36 +236,16 @@ type PiperunCmd struct {
 // - https://dev.azure.com/{org}/{project}/_build/results?buildId=123
 // - https://{org}.visualstudio.com/{project}/_build?definitionId=123
 func parseADOUrl(rawURL string) (orgURL string, project string, queryParams url.Values, err error) {
-	parsed, err := url.Parse(rawURL)
+	orgURL, pathParts, queryParams, err := utils.ResolveADOUrl(rawURL)
 	if err != nil {
 		return "", "", nil, err
 	}
 
-	pathParts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
-
-	// Check if it's dev.azure.com format (org is in path)
-	if strings.Contains(parsed.Host, "dev.azure.com") {
-		// Format: https://dev.azure.com/{org}/{project}/...
-		if len(pathParts) < 2 {
-			return "", "", nil, fmt.Errorf("invalid ADO URL - expected org and project in path")
-		}
-		org := pathParts[0]
-		project = pathParts[1]
-		orgURL = fmt.Sprintf("https://dev.azure.com/%s", org)
-	} else if strings.Contains(parsed.Host, "visualstudio.com") {
-		// Format: https://{org}.visualstudio.com/{project}/...
-		// Org is part of hostname
-		orgURL = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
-		if len(pathParts) < 1 {
-			return "", "", nil, fmt.Errorf("invalid ADO URL - expected project in path")
-		}
-		project = pathParts[0]
-	} else {
-		return "", "", nil, fmt.Errorf("unrecognized ADO URL format")
+	if len(pathParts) < 1 {
+		return "", "", nil, fmt.Errorf("invalid ADO URL - expected project in path")
 	}
 
-	queryParams = parsed.Query()
-	return orgURL, project, queryParams, nil
+	return orgURL, pathParts[0], queryParams, nil
 }
 
 func (p *PiperunCmd) Run() {
@@ -129,72 +254,283 @@ func (p *PiperunCmd) Run() {
 		p.runPipeline()
 	case "monitor-trigger":
 		p.monitorAndTrigger()
+	case "monitor-status":
+		p.monitorStatus()
+	case "logs":
+		p.showLogs()
+	case "why":
+		p.explainFailure()
+	case "artifacts":
+		p.artifacts()
+	case "cancel":
+		p.cancelRun()
+	case "retry":
+		p.retryRun()
+	case "rerun":
+		p.rerunRun()
+	case "approve":
+		p.approveRun()
+	case "list":
+		p.listPipelines()
+	case "runs":
+		p.listRuns()
+	case "plan":
+		p.runPlan()
 	default:
-		fmt.Println(piperunHelp)
+		fmt.Print(piperunHelp)
 		os.Exit(1)
 	}
 }
 
 func (p *PiperunCmd) runPipeline() {
 	if p.PipelineURL == "" {
-		fmt.Println(piperunRunHelp)
+		fmt.Print(piperunRunHelp)
 		os.Exit(1)
 	}
 
 	// Get PAT (optional - if not provided, will use az login identity)
 	pat := utils.GetPAT(p.PAT)
 
-	orgURL, project, queryParams, err := parseADOUrl(p.PipelineURL)
+	orgURL, project, definitionID, err := resolveDefinition(pat, p.PipelineURL)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing URL: %s\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
 	}
 
-	definitionID := queryParams.Get("definitionId")
-	if definitionID == "" {
-		fmt.Fprintln(os.Stderr, "Error: Could not extract definitionId from URL")
+	identity, err := utils.ConfirmIdentity(p.PAT, orgURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
 	}
 
+	opts, err := p.runOptions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	timeout := p.waitTimeout()
+
 	fmt.Printf("Triggering pipeline: %s\n", p.PipelineURL)
 	fmt.Printf("Project: %s, Definition ID: %s\n", project, definitionID)
+	if opts.Branch != "" {
+		fmt.Printf("Branch: %s\n", opts.Branch)
+	}
+	if opts.Commit != "" {
+		fmt.Printf("Commit: %s\n", opts.Commit)
+	}
+	for _, pair := range keyValuePairs(opts.Parameters) {
+		fmt.Printf("Parameter: %s\n", pair)
+	}
+	for _, pair := range keyValuePairs(opts.Variables) {
+		fmt.Printf("Variable: %s\n", pair)
+	}
+	if len(opts.StagesToSkip) > 0 {
+		fmt.Printf("Skipping stages: %s\n", strings.Join(opts.StagesToSkip, ", "))
+	}
 
-	// Run pipeline using az CLI (with PAT if provided, otherwise az login)
-	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "pipelines", "run",
-		"--id", definitionID,
-		"--org", orgURL,
-		"--project", project,
-		"-o", "json",
-	)
-
+	build, err := triggerPipeline(pat, orgURL, project, definitionID, opts)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Failed to trigger pipeline:")
-		fmt.Fprintln(os.Stderr, stderr)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing response: %s\n", err)
+	auditAction(identity, p.PAT != "", buildWebURL(orgURL, project, build.ID), "run pipeline "+definitionID)
+
+	fmt.Println("\nSuccessfully triggered pipeline!")
+	fmt.Printf("Build ID: %d\n", build.ID)
+	fmt.Printf("URL: %s\n", buildWebURL(orgURL, project, build.ID))
+
+	if p.Wait {
+		p.waitAndExit(pat, orgURL, project, build.ID, timeout)
+	}
+}
+
+// waitTimeout returns the --timeout of a waiting command, 0 when not set
+func (p *PiperunCmd) waitTimeout() time.Duration {
+	if p.Timeout == "" {
+		return 0
+	}
+	if !p.Wait {
+		fmt.Fprintln(os.Stderr, "Error: --timeout requires --wait")
+		os.Exit(1)
+	}
+	d, err := time.ParseDuration(p.Timeout)
+	if err != nil || d <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%s' (e.g. 30m or 2h)\n", p.Timeout)
 		os.Exit(1)
 	}
+	return d
+}
 
-	buildID := ""
-	if id, ok := result["id"].(float64); ok {
-		buildID = fmt.Sprintf("%.0f", id)
+// maxRetries returns the number of failed status checks in a row to tolerate
+func (p *PiperunCmd) maxRetries() int {
+	if p.MaxRetries <= 0 {
+		return 5
 	}
+	return p.MaxRetries
+}
 
-	fmt.Println("\nSuccessfully triggered pipeline!")
-	fmt.Printf("Build ID: %s\n", buildID)
-	fmt.Printf("URL: %s/%s/_build/results?buildId=%s&view=results\n", orgURL, project, buildID)
+// waitAndExit waits for a build to complete and exits with the code of its result
+func (p *PiperunCmd) waitAndExit(pat, orgURL, project string, buildID int, timeout time.Duration) {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 30
+	}
+
+	fmt.Printf("\nWaiting for build %d (checking every %d seconds)...\n", buildID, interval)
+	build, err := waitForBuild(pat, orgURL, project, buildID, interval, timeout, p.maxRetries())
+	if errors.Is(err, errBuildTimeout) {
+		fmt.Fprintf(os.Stderr, "\nError: %s after %s (the build keeps running)\n", err, timeout)
+		os.Exit(runExitTimeout)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "\nError: %s (the build keeps running)\n", err)
+		os.Exit(runExitNoStatus)
+	}
+
+	fmt.Printf("\nBuild %s completed with result: %s\n", build.BuildNumber, build.Result)
+	code, ok := runExitCodes[build.Result]
+	if !ok {
+		code = runExitFailed
+	}
+	os.Exit(code)
+}
+
+// runOptions collects the run options of 'release run'. Parameters are read from
+// the definition URL query string, then --params-file, then --parameter, later
+// sources overriding earlier ones.
+func (p *PiperunCmd) runOptions() (runOptions, error) {
+	opts := runOptions{
+		Branch:     p.Branch,
+		Commit:     p.Commit,
+		Parameters: map[string]string{},
+		Variables:  map[string]string{},
+	}
+
+	// Pipeline names and IDs have no query string
+	queryParams := url.Values{}
+	if isURL(p.PipelineURL) {
+		_, _, q, err := parseADOUrl(p.PipelineURL)
+		if err != nil {
+			return opts, err
+		}
+		queryParams = q
+	}
+	for key, values := range queryParams {
+		// Portal URLs carry view state such as _a=summary
+		if urlQueryKeys[key] || strings.HasPrefix(key, "_") || len(values) == 0 {
+			continue
+		}
+		opts.Parameters[key] = values[len(values)-1]
+	}
+	if opts.Branch == "" {
+		opts.Branch = queryParams.Get("branch")
+	}
+
+	if p.ParamsFile != "" {
+		params, err := readParamsFile(p.ParamsFile)
+		if err != nil {
+			return opts, err
+		}
+		for k, v := range params {
+			opts.Parameters[k] = v
+		}
+	}
+
+	for _, pair := range p.Parameters {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return opts, fmt.Errorf("invalid parameter '%s' (expected key=value)", pair)
+		}
+		opts.Parameters[k] = v
+	}
+	for _, pair := range p.Variables {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return opts, fmt.Errorf("invalid variable '%s' (expected key=value)", pair)
+		}
+		opts.Variables[k] = v
+	}
+
+	for _, list := range p.StagesToSkip {
+		for _, stage := range strings.Split(list, ",") {
+			if stage = strings.TrimSpace(stage); stage != "" {
+				opts.StagesToSkip = append(opts.StagesToSkip, stage)
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// readParamsFile reads pipeline parameters from a JSON object. String values are
+// used as they are, other values (numbers, booleans, lists, objects) as JSON.
+func readParamsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read params file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("could not parse params file %s: %w", path, err)
+	}
+	return stringifyParams(raw)
+}
+
+// stringifyParams converts parameter values to the strings a run is queued with
+func stringifyParams(raw map[string]interface{}) (map[string]string, error) {
+	params := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if str, ok := v.(string); ok {
+			params[k] = str
+			continue
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for parameter '%s': %w", k, err)
+		}
+		params[k] = string(encoded)
+	}
+	return params, nil
+}
+
+// watchedBuild is a build that monitor-trigger waits for
+type watchedBuild struct {
+	url     string
+	orgURL  string
+	project string
+	buildID string
+	build   *buildRun
+	// waiting describes the approvals and checks the build is waiting on
+	waiting string
+}
+
+// done reports whether the build has completed
+func (w *watchedBuild) done() bool {
+	return w.build != nil && w.build.Status == "completed"
+}
+
+// pipelineTarget is a pipeline definition that monitor-trigger runs
+type pipelineTarget struct {
+	url          string
+	orgURL       string
+	project      string
+	definitionID string
 }
 
 func (p *PiperunCmd) monitorAndTrigger() {
-	if p.WaitForURL == "" || p.TriggerURL == "" {
-		fmt.Println(piperunMonitorHelp)
+	if len(p.WaitForURLs) == 0 || len(p.TriggerURLs) == 0 {
+		fmt.Print(piperunMonitorHelp)
 		os.Exit(1)
 	}
 
+	// A detached monitor gets the -t PAT of its parent through the environment
+	if p.PAT == "" {
+		p.PAT = os.Getenv(monitorPATEnv)
+	}
+
 	// Get PAT (optional - if not provided, will use az login identity)
 	pat := utils.GetPAT(p.PAT)
 
@@ -203,112 +539,236 @@ func (p *PiperunCmd) monitorAndTrigger() {
 		interval = 30
 	}
 
-	waitOrgURL, project, queryParams, err := parseADOUrl(p.WaitForURL)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing wait URL: %s\n", err)
-		os.Exit(1)
+	var waits []*watchedBuild
+	for _, waitURL := range p.WaitForURLs {
+		orgURL, project, queryParams, err := parseADOUrl(waitURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing wait URL: %s\n", err)
+			os.Exit(1)
+		}
+		buildID := queryParams.Get("buildId")
+		if buildID == "" {
+			fmt.Fprintf(os.Stderr, "Error: Could not extract buildId from wait URL %s\n", waitURL)
+			os.Exit(1)
+		}
+		waits = append(waits, &watchedBuild{url: waitURL, orgURL: orgURL, project: project, buildID: buildID})
 	}
 
-	buildID := queryParams.Get("buildId")
-	if buildID == "" {
-		fmt.Fprintln(os.Stderr, "Error: Could not extract buildId from wait URL")
-		os.Exit(1)
+	var targets []pipelineTarget
+	for _, triggerURL := range p.TriggerURLs {
+		orgURL, project, definitionID, err := resolveDefinition(pat, triggerURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Trigger pipeline %s: %s\n", triggerURL, err)
+			os.Exit(1)
+		}
+		targets = append(targets, pipelineTarget{url: triggerURL, orgURL: orgURL, project: project, definitionID: definitionID})
 	}
 
-	triggerOrgURL, triggerProject, triggerParams, err := parseADOUrl(p.TriggerURL)
+	identity, err := utils.ConfirmIdentity(p.PAT, targets[0].orgURL)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing trigger URL: %s\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
 	}
 
-	definitionID := triggerParams.Get("definitionId")
-	if definitionID == "" {
-		fmt.Fprintln(os.Stderr, "Error: Could not extract definitionId from trigger URL")
-		os.Exit(1)
+	var timeout time.Duration
+	if p.Timeout != "" {
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%s' (e.g. 30m or 2h)\n", p.Timeout)
+			os.Exit(1)
+		}
+		timeout = d
+	}
+
+	maxRetries := p.maxRetries()
+
+	if p.Detach {
+		p.detachMonitor(interval)
+		return
+	}
+
+	// Ctrl-C and termination print the last known state instead of leaving no trace
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	if os.Getenv(monitorStateEnv) != "" {
+		// A detached monitor outlives the terminal it was started from
+		signal.Ignore(syscall.SIGHUP)
+	}
+
+	// finish records the final state and exits
+	finish := func(status, message string, code int) {
+		saveMonitorState(waits, status, message, nil)
+		fmt.Println()
+		printWatchedBuilds(waits)
+		if message != "" {
+			fmt.Fprintf(os.Stderr, "\nError: %s\n", message)
+		}
+		os.Exit(code)
+	}
+
+	mode := "all"
+	if p.WaitAny {
+		mode = "any"
 	}
 
 	fmt.Println("Starting pipeline monitor...")
-	fmt.Printf("Monitoring: %s\n", p.WaitForURL)
-	fmt.Printf("Will trigger: %s\n", p.TriggerURL)
-	fmt.Printf("Check interval: %d seconds\n\n", interval)
+	for _, w := range waits {
+		fmt.Printf("Monitoring: %s\n", w.url)
+	}
+	for _, t := range targets {
+		fmt.Printf("Will trigger: %s\n", t.url)
+	}
+	if len(waits) > 1 {
+		fmt.Printf("Trigger when: %s succeeded\n", mode)
+	}
+	fmt.Printf("Check interval: %d seconds\n", interval)
+	if timeout > 0 {
+		fmt.Printf("Timeout: %s\n", timeout)
+	}
+	fmt.Println()
 
+	deadline := time.Now().Add(timeout)
+	failures := 0
 	for {
-		// Get build status (with PAT if provided, otherwise az login)
-		stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "pipelines", "runs", "show",
-			"--id", buildID,
-			"--org", waitOrgURL,
-			"--project", project,
-			"-o", "json",
-		)
+		checkFailed := false
+		for _, w := range waits {
+			if w.done() {
+				continue
+			}
 
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error checking pipeline status: %s\n", stderr)
-			fmt.Printf("Retrying in %d seconds...\n", interval)
-			time.Sleep(time.Duration(interval) * time.Second)
-			continue
-		}
+			// Get build status (with PAT if provided, otherwise az login)
+			build, err := getBuild(pat, w.orgURL, w.project, w.buildID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking pipeline status: %s\n", err)
+				checkFailed = true
+				continue
+			}
+			w.build = build
 
-		var buildResult map[string]interface{}
-		if err := json.Unmarshal([]byte(stdout), &buildResult); err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing response: %s\n", err)
-			time.Sleep(time.Duration(interval) * time.Second)
-			continue
+			fmt.Printf("Pipeline %s status: %s", w.buildID, build.Status)
+			if build.Result != "" {
+				fmt.Printf(" (result: %s)", build.Result)
+			}
+			fmt.Println()
+
+			// Approvals are best effort, the run is checked again either way
+			waiting := ""
+			if build.Status == "inProgress" {
+				buildID, _ := strconv.Atoi(w.buildID)
+				if records, err := getTimeline(pat, w.orgURL, w.project, buildID); err == nil {
+					if checks, err := pendingChecks(pat, w.orgURL, w.project, records); err == nil && len(checks) > 0 {
+						waiting = describeChecks(checks)
+					}
+				}
+			}
+			if waiting != "" && waiting != w.waiting {
+				fmt.Printf("Pipeline %s is %s\n", w.buildID, waiting)
+				fmt.Printf("    Approve with: defenders release approve %s\n", w.url)
+			}
+			w.waiting = waiting
 		}
 
-		status := ""
-		if s, ok := buildResult["status"].(string); ok {
-			status = s
+		trigger, finished := waitOutcome(waits, p.WaitAny)
+		if trigger {
+			break
 		}
-
-		result := ""
-		if r, ok := buildResult["result"].(string); ok {
-			result = r
+		if finished {
+			finish("failed", fmt.Sprintf("Not triggering - %s of the monitored pipelines had to succeed", mode), 1)
 		}
 
-		fmt.Printf("Pipeline %s status: %s", buildID, status)
-		if result != "" {
-			fmt.Printf(" (result: %s)", result)
+		// Failed checks are retried with a growing delay, up to maxRetries in a row
+		delay := time.Duration(interval) * time.Second
+		currTime := time.Now().Format("2006-01-02 15:04:05")
+		var waiting []string
+		for _, w := range waits {
+			if w.waiting != "" && !w.done() {
+				waiting = append(waiting, "Pipeline "+w.buildID+" is "+w.waiting)
+			}
 		}
-		fmt.Println()
-
-		if status == "completed" {
-			fmt.Printf("\nPipeline %s completed with result: %s\n", buildID, result)
-
-			if result == "succeeded" {
-				fmt.Println("Triggering second pipeline...")
-
-				stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "pipelines", "run",
-					"--id", definitionID,
-					"--org", triggerOrgURL,
-					"--project", triggerProject,
-					"-o", "json",
-				)
+		if checkFailed {
+			failures++
+			if failures > maxRetries {
+				finish("error", fmt.Sprintf("Giving up after %d failed status checks in a row", maxRetries), 1)
+			}
+			delay = retryDelay(failures)
+			fmt.Printf("[%s] Status check failed (%d/%d). Retrying in %s...\n", currTime, failures, maxRetries, delay)
+		} else if len(waiting) > 0 {
+			failures = 0
+			fmt.Printf("[%s] Pipeline waiting for approval or checks. Checking again in %d seconds...\n", currTime, interval)
+		} else {
+			failures = 0
+			fmt.Printf("[%s] Pipeline still running. Checking again in %d seconds...\n", currTime, interval)
+		}
+		saveMonitorState(waits, "running", strings.Join(waiting, "; "), nil)
 
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to trigger pipeline: %s\n", stderr)
-					os.Exit(1)
-				}
+		if timeout > 0 && time.Now().Add(delay).After(deadline) {
+			finish("timed out", fmt.Sprintf("Timed out after %s, nothing was triggered", timeout), runExitTimeout)
+		}
 
-				var triggerResult map[string]interface{}
-				json.Unmarshal([]byte(stdout), &triggerResult)
+		select {
+		case <-time.After(delay):
+		case sig := <-stop:
+			finish("interrupted", fmt.Sprintf("Interrupted (%s), nothing was triggered", sig), 130)
+		}
+	}
 
-				newBuildID := ""
-				if id, ok := triggerResult["id"].(float64); ok {
-					newBuildID = fmt.Sprintf("%.0f", id)
-				}
+	fmt.Println()
+	for _, w := range waits {
+		if w.done() {
+			fmt.Printf("Pipeline %s completed with result: %s\n", w.buildID, w.build.Result)
+		}
+	}
+	fmt.Printf("Triggering %d pipeline(s)...\n", len(targets))
+
+	builds := make([]*buildRun, len(targets))
+	errs := make([]error, len(targets))
+	utils.RunParallel(len(targets), len(targets), func(i int) {
+		t := targets[i]
+		builds[i], errs[i] = triggerPipeline(pat, t.orgURL, t.project, t.definitionID, runOptions{})
+	})
+
+	failed := 0
+	triggered := []string{}
+	for i, t := range targets {
+		if errs[i] != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Failed to trigger pipeline %s: %s\n", t.definitionID, errs[i])
+			continue
+		}
+		buildURL := buildWebURL(t.orgURL, t.project, builds[i].ID)
+		triggered = append(triggered, buildURL)
+		auditAction(identity, p.PAT != "", buildURL, "run pipeline "+t.definitionID)
+		fmt.Printf("Successfully triggered pipeline %d\n", builds[i].ID)
+		fmt.Printf("URL: %s\n", buildURL)
+	}
+	if failed > 0 {
+		saveMonitorState(waits, "error", fmt.Sprintf("%d of %d pipelines could not be triggered", failed, len(targets)), triggered)
+		os.Exit(1)
+	}
+	saveMonitorState(waits, "triggered", "", triggered)
+}
 
-				fmt.Printf("Successfully triggered pipeline %s\n", newBuildID)
-				fmt.Printf("URL: %s/%s/_build/results?buildId=%s&view=results\n", triggerOrgURL, triggerProject, newBuildID)
-			} else {
-				fmt.Printf("Pipeline %s failed with result: %s\n", buildID, result)
-			}
-			break
+// waitOutcome decides whether monitor-trigger should trigger now, and whether
+// every monitored build has finished. With waitAny, one succeeded build is enough;
+// otherwise all of them must succeed, and one failure ends the wait.
+func waitOutcome(waits []*watchedBuild, waitAny bool) (trigger, finished bool) {
+	succeeded, completed := 0, 0
+	for _, w := range waits {
+		if !w.done() {
+			continue
+		}
+		completed++
+		if w.build.Result == "succeeded" {
+			succeeded++
+		} else if !waitAny {
+			return false, true
 		}
+	}
 
-		currTime := time.Now().Format("2006-01-02 15:04:05")
-		fmt.Printf("[%s] Pipeline still running. Checking again in %d seconds...\n", currTime, interval)
-		time.Sleep(time.Duration(interval) * time.Second)
+	if waitAny {
+		return succeeded > 0, completed == len(waits)
 	}
+	return succeeded == len(waits), completed == len(waits)
 }
 
 // ParsePiperunArgs parses command line arguments for piperun command
@@ -325,12 +785,21 @@ func ParsePiperunArgs(args []string) *PiperunCmd {
 	cmd.Subcommand = args[0]
 
 	if cmd.Subcommand == "-h" || cmd.Subcommand == "--help" {
-		fmt.Println(piperunHelp)
+		fmt.Print(piperunHelp)
 		os.Exit(0)
 	}
 
 	positionalArgs := []string{}
 
+	// nextValue returns the value following flag i, advancing the index
+	nextValue := func(i *int) string {
+		if *i+1 < len(args) {
+			*i++
+			return args[*i]
+		}
+		return ""
+	}
+
 	for i := 1; i < len(args); i++ {
 		arg := args[i]
 		switch {
@@ -354,14 +823,148 @@ func ParsePiperunArgs(args []string) *PiperunCmd {
 			if val, err := strconv.Atoi(strings.TrimPrefix(arg, "--interval=")); err == nil {
 				cmd.Interval = val
 			}
+		case arg == "-f" || arg == "--force":
+			utils.Force = true
+		case arg == "-b" || arg == "--branch":
+			cmd.Branch = nextValue(&i)
+		case strings.HasPrefix(arg, "--branch="):
+			cmd.Branch = strings.TrimPrefix(arg, "--branch=")
+		case arg == "--commit":
+			cmd.Commit = nextValue(&i)
+		case strings.HasPrefix(arg, "--commit="):
+			cmd.Commit = strings.TrimPrefix(arg, "--commit=")
+		case arg == "-p" || arg == "--parameter":
+			cmd.Parameters = append(cmd.Parameters, nextValue(&i))
+		case strings.HasPrefix(arg, "--parameter="):
+			cmd.Parameters = append(cmd.Parameters, strings.TrimPrefix(arg, "--parameter="))
+		case arg == "-v" || arg == "--variable":
+			cmd.Variables = append(cmd.Variables, nextValue(&i))
+		case strings.HasPrefix(arg, "--variable="):
+			cmd.Variables = append(cmd.Variables, strings.TrimPrefix(arg, "--variable="))
+		case arg == "--params-file":
+			cmd.ParamsFile = nextValue(&i)
+		case strings.HasPrefix(arg, "--params-file="):
+			cmd.ParamsFile = strings.TrimPrefix(arg, "--params-file=")
+		case arg == "-w" || arg == "--wait":
+			cmd.Wait = true
+		case arg == "--timeout":
+			cmd.Timeout = nextValue(&i)
+		case strings.HasPrefix(arg, "--timeout="):
+			cmd.Timeout = strings.TrimPrefix(arg, "--timeout=")
+		case arg == "--max-retries":
+			if val, err := strconv.Atoi(nextValue(&i)); err == nil {
+				cmd.MaxRetries = val
+			}
+		case strings.HasPrefix(arg, "--max-retries="):
+			if val, err := strconv.Atoi(strings.TrimPrefix(arg, "--max-retries=")); err == nil {
+				cmd.MaxRetries = val
+			}
+		case arg == "--task":
+			cmd.Task = nextValue(&i)
+		case strings.HasPrefix(arg, "--task="):
+			cmd.Task = strings.TrimPrefix(arg, "--task=")
+		case arg == "--format":
+			cmd.Format = nextValue(&i)
+		case strings.HasPrefix(arg, "--format="):
+			cmd.Format = strings.TrimPrefix(arg, "--format=")
+		case arg == "--lines":
+			if val, err := strconv.Atoi(nextValue(&i)); err == nil {
+				cmd.Lines = val
+			}
+		case strings.HasPrefix(arg, "--lines="):
+			if val, err := strconv.Atoi(strings.TrimPrefix(arg, "--lines=")); err == nil {
+				cmd.Lines = val
+			}
+		case arg == "-o" || arg == "--output":
+			cmd.Output = nextValue(&i)
+		case strings.HasPrefix(arg, "--output="):
+			cmd.Output = strings.TrimPrefix(arg, "--output=")
+		case arg == "--download":
+			cmd.Download = nextValue(&i)
+		case strings.HasPrefix(arg, "--download="):
+			cmd.Download = strings.TrimPrefix(arg, "--download=")
+		case arg == "--to":
+			cmd.To = nextValue(&i)
+		case strings.HasPrefix(arg, "--to="):
+			cmd.To = strings.TrimPrefix(arg, "--to=")
+		case arg == "--sha256":
+			cmd.SHA256 = nextValue(&i)
+		case strings.HasPrefix(arg, "--sha256="):
+			cmd.SHA256 = strings.TrimPrefix(arg, "--sha256=")
+		case arg == "--stage":
+			cmd.Stage = nextValue(&i)
+		case strings.HasPrefix(arg, "--stage="):
+			cmd.Stage = strings.TrimPrefix(arg, "--stage=")
+		case arg == "--comment":
+			cmd.Comment = nextValue(&i)
+		case strings.HasPrefix(arg, "--comment="):
+			cmd.Comment = strings.TrimPrefix(arg, "--comment=")
+		case arg == "--reject":
+			cmd.Reject = true
+		case arg == "--name":
+			cmd.NamePattern = nextValue(&i)
+		case strings.HasPrefix(arg, "--name="):
+			cmd.NamePattern = strings.TrimPrefix(arg, "--name=")
+		case arg == "--folder":
+			cmd.Folder = nextValue(&i)
+		case strings.HasPrefix(arg, "--folder="):
+			cmd.Folder = strings.TrimPrefix(arg, "--folder=")
+		case arg == "--result":
+			cmd.Result = nextValue(&i)
+		case strings.HasPrefix(arg, "--result="):
+			cmd.Result = strings.TrimPrefix(arg, "--result=")
+		case arg == "--top":
+			if val, err := strconv.Atoi(nextValue(&i)); err == nil {
+				cmd.Top = val
+			}
+		case strings.HasPrefix(arg, "--top="):
+			if val, err := strconv.Atoi(strings.TrimPrefix(arg, "--top=")); err == nil {
+				cmd.Top = val
+			}
+		case arg == "--follow":
+			cmd.Follow = true
+		case arg == "--detach":
+			cmd.Detach = true
+		case arg == "--any":
+			cmd.WaitAny = true
+		case arg == "--all":
+			cmd.WaitAny = false
+		case arg == "--restart":
+			cmd.Restart = true
+		case arg == "--stages-to-skip":
+			cmd.StagesToSkip = append(cmd.StagesToSkip, nextValue(&i))
+		case strings.HasPrefix(arg, "--stages-to-skip="):
+			cmd.StagesToSkip = append(cmd.StagesToSkip, strings.TrimPrefix(arg, "--stages-to-skip="))
 		case arg == "-h" || arg == "--help":
 			switch cmd.Subcommand {
 			case "run":
-				fmt.Println(piperunRunHelp)
+				fmt.Print(piperunRunHelp)
 			case "monitor-trigger":
-				fmt.Println(piperunMonitorHelp)
+				fmt.Print(piperunMonitorHelp)
+			case "monitor-status":
+				fmt.Print(monitorStatusHelp)
+			case "logs":
+				fmt.Print(releaseLogsHelp)
+			case "why":
+				fmt.Print(releaseWhyHelp)
+			case "artifacts":
+				fmt.Print(releaseArtifactsHelp)
+			case "cancel":
+				fmt.Print(releaseCancelHelp)
+			case "retry":
+				fmt.Print(releaseRetryHelp)
+			case "rerun":
+				fmt.Print(releaseRerunHelp)
+			case "approve":
+				fmt.Print(releaseApproveHelp)
+			case "list":
+				fmt.Print(releaseListHelp)
+			case "runs":
+				fmt.Print(releaseRunsHelp)
+			case "plan":
+				fmt.Print(releasePlanHelp)
 			default:
-				fmt.Println(piperunHelp)
+				fmt.Print(piperunHelp)
 			}
 			os.Exit(0)
 		default:
@@ -373,16 +976,31 @@ func ParsePiperunArgs(args []string) *PiperunCmd {
 
 	// Assign positional args based on subcommand
 	switch cmd.Subcommand {
-	case "run":
+	case "run", "logs", "why", "artifacts", "cancel", "retry", "rerun", "approve", "runs":
 		if len(positionalArgs) >= 1 {
 			cmd.PipelineURL = positionalArgs[0]
 		}
 	case "monitor-trigger":
+		// Build URLs (buildId) are waited for; definition URLs (definitionId),
+		// pipeline names and IDs are triggered
+		for _, arg := range positionalArgs {
+			_, _, queryParams, err := parseADOUrl(arg)
+			if isURL(arg) && (err != nil || queryParams.Get("definitionId") == "") {
+				cmd.WaitForURLs = append(cmd.WaitForURLs, arg)
+			} else {
+				cmd.TriggerURLs = append(cmd.TriggerURLs, arg)
+			}
+		}
+	case "monitor-status":
+		if len(positionalArgs) >= 1 {
+			cmd.MonitorID = positionalArgs[0]
+		}
+	case "plan":
 		if len(positionalArgs) >= 1 {
-			cmd.WaitForURL = positionalArgs[0]
+			cmd.PlanAction = positionalArgs[0]
 		}
 		if len(positionalArgs) >= 2 {
-			cmd.TriggerURL = positionalArgs[1]
+			cmd.PlanFile = positionalArgs[1]
 		}
 	}
 
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"defenders-cli/internal/utils"
+)
+
+// bulkVoteResult is the outcome of voting on one PR during a bulk vote
+type bulkVoteResult struct {
+	ref     string
+	pr      *pullRequest
+	err     error
+	warning string
+}
+
+// hasListFilters reports whether any 'pr list' filter was given
+func (p *PrhandlerCmd) hasListFilters() bool {
+	return p.Mine || p.Author != "" || p.Reviewing || p.WaitingOnMe || p.Repo != "" ||
+		p.Target != "" || p.Status != "" || p.PassingBuilds
+}
+
+// voteTargets returns the PRs to vote on: the positional URLs or IDs ('-' reads
+// them from stdin), or the PRs matching the list filters when none are given
+func (p *PrhandlerCmd) voteTargets(pat string) ([]string, error) {
+	var refs []string
+	for _, arg := range p.PRURLs {
+		if arg != "-" {
+			refs = append(refs, arg)
+			continue
+		}
+
+		// stdin is used up by the PR list, confirmations are read from the terminal
+		if !utils.Force {
+			if err := utils.PromptFromTerminal(); err != nil {
+				return nil, fmt.Errorf("%w - use --force to vote on PRs read from stdin without confirmation", err)
+			}
+		}
+
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				refs = append(refs, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("could not read PR URLs from stdin: %w", err)
+		}
+	}
+
+	if len(refs) > 0 || !p.hasListFilters() {
+		return refs, nil
+	}
+
+	prs, err := p.queryPRs(pat)
+	if err != nil {
+		return nil, err
+	}
+
+	var evaluations [][]policyEvaluation
+	if p.PassingBuilds {
+		evaluations = fetchPolicies(pat, prs)
+	}
+
+	for i := range prs {
+		if p.PassingBuilds && !buildsPassing(evaluations[i]) {
+			continue
+		}
+		refs = append(refs, prWebURL(&prs[i]))
+	}
+
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no PRs match the given filters")
+	}
+	return refs, nil
+}
+
+// buildsPassing reports whether a PR has build policies and all of them succeeded
+func buildsPassing(policies []policyEvaluation) bool {
+	builds := 0
+	for _, p := range policies {
+		if !p.Configuration.IsEnabled || p.Configuration.Type.DisplayName != "Build" {
+			continue
+		}
+		builds++
+		if p.Status != "approved" {
+			return false
+		}
+	}
+	return builds > 0
+}
+
+// bulkVote applies a vote to several PRs using a bounded worker pool
+func (p *PrhandlerCmd) bulkVote(pat string, refs []string, vote prVote) {
+	fmt.Printf("%d PRs selected:\n", len(refs))
+	for _, ref := range refs {
+		fmt.Printf("  %s\n", ref)
+	}
+	fmt.Println()
+
+	// The identity is confirmed once for every organization the PRs belong to,
+	// PR IDs belong to the configured organization
+	orgs := make([]string, len(refs))
+	var distinct []string
+	identities := make(map[string]*utils.Identity)
+	for i, ref := range refs {
+		orgs[i] = strings.TrimRight(utils.GetOrganization(""), "/")
+		if resolved, _, _, err := utils.ResolveADOUrl(ref); err == nil {
+			orgs[i] = resolved
+		}
+		if _, ok := identities[orgs[i]]; !ok {
+			identities[orgs[i]] = nil
+			distinct = append(distinct, orgs[i])
+		}
+	}
+	for _, orgURL := range distinct {
+		if len(distinct) > 1 {
+			fmt.Printf("Organization: %s\n", orgURL)
+		}
+		identity, err := utils.ConfirmIdentity(p.PAT, orgURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		identities[orgURL] = identity
+	}
+
+	if !utils.AskUser("Apply '%s' to %d PRs? [y/N]: ", vote.azVote, len(refs)) {
+		fmt.Println("Cancelled.")
+		return
+	}
+
+	workers := p.Parallel
+	if workers <= 0 {
+		workers = 4
+	}
+
+	results := make([]bulkVoteResult, len(refs))
+	utils.RunParallel(workers, len(refs), func(i int) {
+		results[i] = p.voteOn(pat, refs[i], vote)
+	})
+
+	fmt.Println("\nResults:")
+	failed := 0
+	for i, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Printf("  ✗ %s\n      %s\n", r.ref, r.err)
+			continue
+		}
+		auditAction(identities[orgs[i]], p.PAT != "", prWebURL(r.pr), "vote "+vote.azVote)
+		fmt.Printf("  ✓ #%d %s\n", r.pr.PullRequestID, r.pr.Title)
+		if r.warning != "" {
+			fmt.Printf("      Warning: %s\n", r.warning)
+		}
+	}
+
+	fmt.Printf("\n%d of %d PRs %s", len(refs)-failed, len(refs), vote.action)
+	if failed > 0 {
+		fmt.Printf(", %d failed\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println()
+}
+
+// voteOn resolves one PR and applies the vote (and comment) to it
+func (p *PrhandlerCmd) voteOn(pat, ref string, vote prVote) bulkVoteResult {
+	result := bulkVoteResult{ref: ref}
+
+	pr, orgURL, err := resolvePR(pat, ref, "active")
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.pr = pr
+
+	if err := setVote(pat, orgURL, strconv.Itoa(pr.PullRequestID), vote); err != nil {
+		result.err = err
+		return result
+	}
+
+	if p.Comment != "" {
+		if err := postPRComment(pat, pr, p.Comment, vote.threadStatus); err != nil {
+			result.warning = fmt.Sprintf("comment was not posted: %s", err)
+		}
+	}
+
+	return result
+}
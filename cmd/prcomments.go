@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+const prCommentsHelp = `pr comments / comment / reply / resolve - PR comment threads
+
+USAGE:
+  defenders pr comments [<pr-url>] [--all]
+  defenders pr comment [<pr-url>] [--file <path> --line <n>] "text"
+  defenders pr comment [<pr-url>] -m "text"
+  defenders pr reply <thread-id> "text" [--pr <pr-url>]
+  defenders pr resolve <thread-id> [--pr <pr-url>]
+
+SUBCOMMANDS:
+  comments  List active comment threads with file and line context
+  comment   Start a new thread, on a file line when --file and --line are given
+  reply     Reply to a thread
+  resolve   Mark a thread as resolved
+
+FLAGS:
+  --all           Include resolved and closed threads (comments)
+  --file <path>   File path in the repository (comment)
+  --line <n>      Line number in the new version of the file (comment)
+  --pr <pr-url>   PR of the thread (reply, resolve)
+  -m, --comment   Comment or reply text, instead of the last argument
+  -t, --token     Personal Access Token (overrides config/env)
+
+Without a PR URL or ID, the active PR of the current branch is used. Text that
+starts with '-' must follow -- or be passed with -m.
+
+EXAMPLES:
+  defenders pr comments <url>
+  defenders pr comment <url> --file src/main.go --line 42 "This can be nil"
+  defenders pr reply 1234 "Fixed in the latest iteration"
+  defenders pr reply 1234 -- "-1 on this approach"
+  defenders pr resolve 1234 --pr <url>
+`
+
+// commentThread is a PR comment thread
+type commentThread struct {
+	ID            int    `json:"id"`
+	Status        string `json:"status"`
+	IsDeleted     bool   `json:"isDeleted"`
+	ThreadContext *struct {
+		FilePath       string `json:"filePath"`
+		RightFileStart *struct {
+			Line int `json:"line"`
+		} `json:"rightFileStart"`
+		LeftFileStart *struct {
+			Line int `json:"line"`
+		} `json:"leftFileStart"`
+	} `json:"threadContext"`
+	Comments []struct {
+		ID          int    `json:"id"`
+		Content     string `json:"content"`
+		CommentType string `json:"commentType"`
+		IsDeleted   bool   `json:"isDeleted"`
+		Author      struct {
+			DisplayName string `json:"displayName"`
+		} `json:"author"`
+		PublishedDate time.Time `json:"publishedDate"`
+	} `json:"comments"`
+}
+
+// location returns "path:line" for file comments, or an empty string for general comments
+func (t *commentThread) location() string {
+	if t.ThreadContext == nil || t.ThreadContext.FilePath == "" {
+		return ""
+	}
+	path := strings.TrimPrefix(t.ThreadContext.FilePath, "/")
+	switch {
+	case t.ThreadContext.RightFileStart != nil:
+		return fmt.Sprintf("%s:%d", path, t.ThreadContext.RightFileStart.Line)
+	case t.ThreadContext.LeftFileStart != nil:
+		return fmt.Sprintf("%s:%d (deleted line)", path, t.ThreadContext.LeftFileStart.Line)
+	default:
+		return path
+	}
+}
+
+// isSystem reports whether the thread only holds system messages (votes, pushes, ...)
+func (t *commentThread) isSystem() bool {
+	for _, c := range t.Comments {
+		if c.CommentType != "system" {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *PrhandlerCmd) listComments() {
+	pat := utils.GetPAT(p.PAT)
+
+	pr, _, err := resolvePR(pat, p.PRURL, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	var data struct {
+		Value []commentThread `json:"value"`
+	}
+	if err := utils.ADORequest(pat, "GET", pr.URL+"/threads?api-version=7.1", nil, &data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get comment threads: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("PR #%d: %s\n\n", pr.PullRequestID, pr.Title)
+
+	shown := 0
+	for _, t := range data.Value {
+		if t.IsDeleted || t.isSystem() {
+			continue
+		}
+		if !p.ShowAll && t.Status != "active" && t.Status != "pending" {
+			continue
+		}
+		shown++
+
+		header := fmt.Sprintf("Thread %d [%s]", t.ID, t.Status)
+		if loc := t.location(); loc != "" {
+			header += " " + loc
+		}
+		fmt.Println(header)
+		for _, c := range t.Comments {
+			if c.IsDeleted || c.CommentType == "system" {
+				continue
+			}
+			fmt.Printf("  %s (%s):\n", c.Author.DisplayName, c.PublishedDate.Local().Format("2006-01-02 15:04"))
+			for _, line := range strings.Split(strings.TrimSpace(c.Content), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+		fmt.Println()
+	}
+
+	if shown == 0 {
+		fmt.Println("No active comment threads.")
+	}
+}
+
+func (p *PrhandlerCmd) addComment() {
+	// The text comes from -m or the last positional argument, an optional first one is the PR
+	text, ref := p.Comment, ""
+	if text == "" {
+		if len(p.PRURLs) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: Comment text is required")
+			fmt.Print(prCommentsHelp)
+			os.Exit(1)
+		}
+		text = p.PRURLs[len(p.PRURLs)-1]
+		if len(p.PRURLs) > 1 {
+			ref = p.PRURLs[0]
+		} else if isPRRef(text) {
+			// A lone PR URL or ID is a forgotten text, not a comment to post
+			fmt.Fprintln(os.Stderr, "Error: Comment text is required")
+			fmt.Print(prCommentsHelp)
+			os.Exit(1)
+		}
+	} else if len(p.PRURLs) > 0 {
+		ref = p.PRURLs[0]
+	}
+
+	if (p.File == "") != (p.Line == 0) {
+		fmt.Fprintln(os.Stderr, "Error: --file and --line must be used together")
+		os.Exit(1)
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	pr, orgURL, err := resolvePR(pat, ref, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	identity := p.confirmIdentity(orgURL)
+
+	body := map[string]interface{}{
+		"comments": []map[string]interface{}{
+			{"parentCommentId": 0, "content": text, "commentType": "text"},
+		},
+		"status": "active",
+	}
+	if p.File != "" {
+		position := map[string]int{"line": p.Line, "offset": 1}
+		body["threadContext"] = map[string]interface{}{
+			"filePath":       "/" + strings.TrimPrefix(p.File, "/"),
+			"rightFileStart": position,
+			"rightFileEnd":   position,
+		}
+	}
+
+	var thread commentThread
+	if err := utils.ADORequest(pat, "POST", pr.URL+"/threads?api-version=7.1", body, &thread); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to add comment: %s\n", err)
+		os.Exit(1)
+	}
+	auditAction(identity, p.PAT != "", prWebURL(pr), fmt.Sprintf("comment (thread %d)", thread.ID))
+
+	fmt.Printf("✓ Comment added to PR #%d (thread %d)\n", pr.PullRequestID, thread.ID)
+}
+
+func (p *PrhandlerCmd) replyToThread() {
+	text := p.Comment
+	if text == "" && len(p.PRURLs) >= 2 {
+		text = p.PRURLs[1]
+	}
+	if len(p.PRURLs) < 1 || text == "" {
+		fmt.Fprintln(os.Stderr, "Error: Thread ID and reply text are required")
+		fmt.Print(prCommentsHelp)
+		os.Exit(1)
+	}
+
+	pr, threadID := p.threadArgs()
+	identity := p.confirmIdentity(pr.org())
+
+	body := map[string]interface{}{
+		"parentCommentId": 1,
+		"content":         text,
+		"commentType":     "text",
+	}
+	reqURL := fmt.Sprintf("%s/threads/%d/comments?api-version=7.1", pr.URL, threadID)
+	if err := utils.ADORequest(utils.GetPAT(p.PAT), "POST", reqURL, body, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to reply: %s\n", err)
+		os.Exit(1)
+	}
+	auditAction(identity, p.PAT != "", prWebURL(pr), fmt.Sprintf("reply to thread %d", threadID))
+
+	fmt.Printf("✓ Replied to thread %d on PR #%d\n", threadID, pr.PullRequestID)
+}
+
+// isPRRef reports whether arg is a PR URL or ID rather than comment text
+func isPRRef(arg string) bool {
+	if _, err := strconv.Atoi(arg); err == nil {
+		return true
+	}
+	_, _, _, _, err := parsePRUrl(arg)
+	return err == nil
+}
+
+// threadArgs returns the PR and thread ID given to reply and resolve
+func (p *PrhandlerCmd) threadArgs() (*pullRequest, int) {
+	if len(p.PRURLs) < 1 {
+		fmt.Fprintln(os.Stderr, "Error: Thread ID is required")
+		fmt.Print(prCommentsHelp)
+		os.Exit(1)
+	}
+
+	threadID, err := strconv.Atoi(p.PRURLs[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid thread ID: %s\n", p.PRURLs[0])
+		os.Exit(1)
+	}
+
+	pr, _, err := resolvePR(utils.GetPAT(p.PAT), p.ThreadPR, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	return pr, threadID
+}
+
+func (p *PrhandlerCmd) markThreadResolved() {
+	pr, threadID := p.threadArgs()
+	identity := p.confirmIdentity(pr.org())
+
+	body := map[string]string{"status": "fixed"}
+	reqURL := fmt.Sprintf("%s/threads/%d?api-version=7.1", pr.URL, threadID)
+	if err := utils.ADORequest(utils.GetPAT(p.PAT), "PATCH", reqURL, body, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to resolve thread: %s\n", err)
+		os.Exit(1)
+	}
+	auditAction(identity, p.PAT != "", prWebURL(pr), fmt.Sprintf("resolve thread %d", threadID))
+
+	fmt.Printf("✓ Thread %d on PR #%d resolved\n", threadID, pr.PullRequestID)
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"defenders-cli/internal/utils"
+)
+
+const prCompleteHelp = `pr complete / abandon / reactivate - Change the status of a Pull Request
+
+USAGE:
+  defenders pr complete [<pr-url>|<pr-id>] [flags]
+  defenders pr abandon [<pr-url>|<pr-id>]
+  defenders pr reactivate [<pr-url>|<pr-id>]
+
+ARGUMENTS:
+  <pr-url>|<pr-id>  PR to change (default: the active PR of the current branch,
+                    or its abandoned PR for reactivate)
+
+FLAGS (complete):
+  --merge-strategy <name>   merge, squash, rebase or semilinear (default: repository setting)
+  --delete-source-branch    Delete the source branch after merge
+  --merge-message <text>    Merge commit message
+  --bypass-policy           Complete even if policies are not satisfied (requires permission)
+  --bypass-reason <text>    Reason for bypassing policies (required with --bypass-policy)
+  -t, --token               Personal Access Token (overrides config/env)
+
+Before completing, the branch policies and required reviewers that still
+block the PR are listed. Completion is refused while anything is blocking
+unless --bypass-policy is given.
+
+EXAMPLES:
+  defenders pr complete <url> --merge-strategy squash --delete-source-branch
+  defenders pr complete <url> --bypass-policy --bypass-reason "Hotfix for incident 1234"
+  defenders pr abandon 123
+  defenders pr reactivate 123
+`
+
+// mergeStrategies maps CLI merge strategy names to ADO completion option values
+var mergeStrategies = map[string]string{
+	"merge":      "noFastForward",
+	"squash":     "squash",
+	"rebase":     "rebase",
+	"semilinear": "rebaseMerge",
+}
+
+func (p *PrhandlerCmd) completePR() {
+	pat := utils.GetPAT(p.PAT)
+
+	strategy := ""
+	if p.MergeStrategy != "" {
+		strategy = mergeStrategies[p.MergeStrategy]
+		if strategy == "" {
+			fmt.Fprintf(os.Stderr, "Error: Unknown merge strategy '%s' (use merge, squash, rebase or semilinear)\n", p.MergeStrategy)
+			os.Exit(1)
+		}
+	}
+
+	if p.BypassPolicy && p.BypassReason == "" {
+		fmt.Fprintln(os.Stderr, "Error: --bypass-reason is required with --bypass-policy")
+		os.Exit(1)
+	}
+
+	pr, orgURL, err := resolvePR(pat, p.PRURL, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if pr.Status != "active" {
+		fmt.Fprintf(os.Stderr, "Error: PR #%d is %s\n", pr.PullRequestID, pr.Status)
+		os.Exit(1)
+	}
+
+	identity := p.confirmIdentity(orgURL)
+
+	prID := strconv.Itoa(pr.PullRequestID)
+	fmt.Printf("Completing PR #%s: %s\n", prID, pr.Title)
+	fmt.Printf("  %s -> %s\n", branchName(pr.SourceRefName), branchName(pr.TargetRefName))
+
+	// Pre-check what still blocks completion
+	policies, err := listPolicies(pat, orgURL, prID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get policies: %s\n", err)
+		os.Exit(1)
+	}
+	reviewers, err := listReviewers(pat, orgURL, prID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get reviewers: %s\n", err)
+		os.Exit(1)
+	}
+
+	blockers := prBlockers(pr, policies, reviewers)
+	if len(blockers) > 0 && !p.BypassPolicy {
+		fmt.Fprintln(os.Stderr, "Error: PR cannot be completed yet:")
+		for _, b := range blockers {
+			fmt.Fprintf(os.Stderr, "  - %s\n", b)
+		}
+		os.Exit(1)
+	}
+	if len(blockers) > 0 {
+		fmt.Printf("Bypassing (%s):\n", p.BypassReason)
+		for _, b := range blockers {
+			fmt.Printf("  - %s\n", b)
+		}
+	}
+
+	options := map[string]interface{}{}
+	if strategy != "" {
+		options["mergeStrategy"] = strategy
+	}
+	if p.DeleteSourceBranch != "" {
+		options["deleteSourceBranch"] = p.DeleteSourceBranch == "true"
+	}
+	if p.MergeMessage != "" {
+		options["mergeCommitMessage"] = p.MergeMessage
+	}
+	if p.BypassPolicy {
+		options["bypassPolicy"] = true
+		options["bypassReason"] = p.BypassReason
+	}
+
+	body := map[string]interface{}{
+		"status":                "completed",
+		"lastMergeSourceCommit": map[string]string{"commitId": pr.LastMergeSourceCommit.CommitID},
+		"completionOptions":     options,
+	}
+	if err := utils.ADORequest(pat, "PATCH", pr.URL+"?api-version=7.1", body, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to complete PR: %s\n", err)
+		os.Exit(1)
+	}
+	action := "complete"
+	if p.BypassPolicy {
+		action += " bypassing policies: " + p.BypassReason
+	}
+	auditAction(identity, p.PAT != "", prWebURL(pr), action)
+
+	fmt.Printf("✓ PR #%s completed\n", prID)
+	fmt.Println(prWebURL(pr))
+}
+
+// setPRStatus abandons or reactivates a PR
+func (p *PrhandlerCmd) setPRStatus(status, action string) {
+	pat := utils.GetPAT(p.PAT)
+
+	// Without a PR reference, reactivate looks for an abandoned PR of the branch
+	current := "active"
+	if status == "active" {
+		current = "abandoned"
+	}
+	pr, orgURL, err := resolvePR(pat, p.PRURL, current)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if pr.Status == status {
+		fmt.Printf("PR #%d is already %s\n", pr.PullRequestID, status)
+		return
+	}
+
+	identity := p.confirmIdentity(orgURL)
+
+	_, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "repos", "pr", "update",
+		"--id", strconv.Itoa(pr.PullRequestID),
+		"--status", status,
+		"--org", orgURL,
+		"-o", "json",
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to update PR status: %s\n", stderr)
+		os.Exit(1)
+	}
+	auditAction(identity, p.PAT != "", prWebURL(pr), "set status "+status)
+
+	fmt.Printf("✓ PR #%d %s\n", pr.PullRequestID, action)
+	fmt.Println(prWebURL(pr))
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"defenders-cli/internal/utils"
+)
+
+const prDiffHelp = `pr diff / checkout - Work with a PR in the local clone
+
+USAGE:
+  defenders pr diff [<pr-url>|<pr-id>] [--name-only]
+  defenders pr checkout <pr-url>|<pr-id>
+
+SUBCOMMANDS:
+  diff      Fetch the PR's source and target branches and show the PR diff
+            (changes on the source branch since it left the target branch)
+  checkout  Create a local branch tracking the PR source branch and switch to it
+
+FLAGS:
+  --name-only  List changed files only (diff)
+  -t, --token  Personal Access Token (overrides config/env)
+
+Must be run inside a clone of the PR's repository.
+
+EXAMPLES:
+  defenders pr diff <url>
+  defenders pr diff 123 --name-only
+  defenders pr checkout <url>
+`
+
+func (p *PrhandlerCmd) diffPR() {
+	pr := p.resolveLocalPR()
+
+	source := branchName(pr.SourceRefName)
+	target := branchName(pr.TargetRefName)
+
+	fmt.Fprintf(os.Stderr, "Fetching %s and %s...\n", source, target)
+	if err := utils.FetchBranches(source, target); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Prefer the commits ADO last merged, so the diff matches what the PR shows
+	sourceCommit := pr.LastMergeSourceCommit.CommitID
+	if sourceCommit == "" {
+		sourceCommit = "origin/" + source
+	}
+	targetCommit := pr.LastMergeTargetCommit.CommitID
+	if targetCommit == "" {
+		targetCommit = "origin/" + target
+	}
+
+	args := []string{"diff"}
+	if p.NameOnly {
+		args = append(args, "--name-only")
+	}
+	args = append(args, targetCommit+"..."+sourceCommit)
+
+	if err := utils.RunCommandWithOutput("git", args...); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: git diff failed: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func (p *PrhandlerCmd) checkoutPR() {
+	if p.PRURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: PR URL or ID is required")
+		fmt.Print(prDiffHelp)
+		os.Exit(1)
+	}
+
+	pr := p.resolveLocalPR()
+	source := branchName(pr.SourceRefName)
+
+	if err := utils.FetchBranches(source); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	var err error
+	if utils.LocalBranchExists(source) {
+		fmt.Printf("Switching to existing branch %s\n", source)
+		err = utils.RunCommandWithOutput("git", "checkout", source)
+		if err == nil && utils.GetUpstream(source) != "origin/"+source {
+			fmt.Printf("Warning: %s does not track origin/%s\n", source, source)
+		}
+	} else {
+		err = utils.RunCommandWithOutput("git", "checkout", "-b", source, "--track", "origin/"+source)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not check out %s: %s\n", source, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Checked out PR #%d: %s\n", pr.PullRequestID, pr.Title)
+}
+
+// resolveLocalPR resolves the PR and makes sure the current clone belongs to its repository
+func (p *PrhandlerCmd) resolveLocalPR() *pullRequest {
+	pr, _, err := resolvePR(utils.GetPAT(p.PAT), p.PRURL, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	remote, err := utils.GetRemoteURL()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	// Both https (.../_git/{repo}) and ssh (...:v3/{org}/{project}/{repo}) remotes end with the repository name
+	remoteRepo := strings.TrimSuffix(strings.TrimRight(remote, "/"), ".git")
+	remoteRepo = remoteRepo[strings.LastIndex(remoteRepo, "/")+1:]
+	if name, err := url.PathUnescape(remoteRepo); err == nil {
+		remoteRepo = name
+	}
+	if !strings.EqualFold(remoteRepo, pr.Repository.Name) {
+		fmt.Fprintf(os.Stderr, "Error: PR #%d belongs to repository %s, but origin is %s\n",
+			pr.PullRequestID, pr.Repository.Name, remote)
+		os.Exit(1)
+	}
+
+	return pr
+}
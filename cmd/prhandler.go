@@ -1,10 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
+	"strconv"
 	"strings"
 
 	"defenders-cli/internal/utils"
@@ -13,48 +12,196 @@ import (
 const prhandlerHelp = `pr - Azure DevOps Pull Request CLI tool
 
 USAGE:
-  defenders pr <action> <pr-url> [-t <token>]
+  defenders pr <action> <pr-url>... [-t <token>]
+  defenders pr <action> - < urls.txt
+  defenders pr <action> [filters]
+  defenders pr <subcommand> [args] [flags]
 
 ACTIONS:
-  --approve  Approve the Pull Request
-  --reset    Reset your vote on the Pull Request
+  --approve                   Approve the Pull Request (vote 10)
+  --approve-with-suggestions  Approve with suggestions (vote 5)
+  --wait-for-author           Wait for author (vote -5)
+  --reject                    Reject the Pull Request (vote -10)
+  --reset                     Reset your vote on the Pull Request (vote 0)
+
+SUBCOMMANDS:
+  update     Change title, description, target, draft state, reviewers,
+             work items and auto-complete of an existing PR
+  complete   Complete (merge) a PR after checking blocking policies
+  abandon    Abandon a PR
+  reactivate Reactivate an abandoned PR
+  list       List PRs: mine, assigned to me, waiting on me
+  comments   List active comment threads of a PR
+  comment    Add a comment, optionally on a file line
+  reply      Reply to a comment thread
+  resolve    Resolve a comment thread
+  diff       Show the PR diff (or changed files) through local git
+  checkout   Check out the PR source branch locally
+  status     Show policies, builds and mergeability (exit code 0 = mergeable)
+  wait       Wait until a PR is approved, passes its policies or is completed
+  requeue    Re-queue expired or failed validation builds
 
 FLAGS:
-  -t, --token  Personal Access Token (overrides config/env - use another user's PAT)
+  -m, --comment   Comment to post on the PR together with the vote
+  -t, --token     Personal Access Token (overrides config/env - use another user's PAT)
+  -p, --parallel  Number of PRs voted on concurrently (default: 4)
+  -f, --force     Do not ask for confirmation before voting on several PRs
+
+BULK VOTING:
+  Several PR URLs or IDs can be given, '-' reads them from stdin (one per
+  line; confirmations are then asked on the terminal, or skipped with
+  --force when there is none), or PRs can be selected with the 'pr list' filters (--mine, --author,
+  --reviewing, --waiting-on-me, --repo, --status, -b/--target) plus
+  --passing-builds to only include PRs whose build policies succeeded.
 
 ARGUMENTS:
-  <pr-url>   Azure DevOps Pull Request URL
+  <pr-url>   Azure DevOps Pull Request URL (or ID in the configured organization)
 
 EXAMPLES:
   defenders pr --approve https://dev.azure.com/org/project/_git/repo/pullrequest/123
   defenders pr --reset https://dev.azure.com/org/project/_git/repo/pullrequest/123
   defenders pr --approve <url> -t <other-user-pat>
+  defenders pr --wait-for-author <url> -m "Please add tests for the new parser"
+  defenders pr --approve <url1> <url2> <url3>
+  defenders pr --approve --author bot@example.com -b develop --passing-builds
+  defenders pr update <url> --title "New title" --add-reviewer user@example.com
+  defenders pr complete <url> --merge-strategy squash --delete-source-branch
+  defenders pr list --waiting-on-me
+  defenders pr wait <url> --until policies-pass --then-complete
 
 AUTHENTICATION:
   PAT with PR approval permissions required.
   Use -t to provide another user's PAT token to approve/vote on their behalf.
+  The identity behind the PAT is shown and must be confirmed when it is not
+  yours. Votes are recorded in the audit log next to the config file.
   Create at: https://msazure.visualstudio.com/_usersSettings/tokens
 `
 
 type PrhandlerCmd struct {
-	Approve bool
-	Reset   bool
-	PRURL   string
-	PAT     string
+	Subcommand string
+	Votes      []string
+	Comment    string
+	PRURL      string
+	PRURLs     []string
+	PAT        string
+	Parallel   int
+
+	// Flags for 'pr update'. Tri-state values are "" (unchanged), "true" or "false".
+	Title              string
+	Description        string
+	Target             string
+	Draft              string
+	AutoComplete       string
+	Squash             string
+	DeleteSourceBranch string
+	MergeMessage       string
+	AddReviewers       []string
+	RemoveReviewers    []string
+	WorkItems          []string
+
+	// Flags for 'pr complete'
+	MergeStrategy string
+	BypassPolicy  bool
+	BypassReason  string
+
+	// Filters for 'pr list' (also select PRs for bulk votes)
+	Mine          bool
+	Author        string
+	PassingBuilds bool
+	Reviewing     bool
+	WaitingOnMe   bool
+	Repo          string
+	Status        string
+	Sort          string
+	Top           int
+
+	// Flags for comment threads
+	File     string
+	Line     int
+	ThreadPR string
+	ShowAll  bool
+
+	// Flags for 'pr diff'
+	NameOnly bool
+
+	// Flags for 'pr wait'
+	Until        string
+	Timeout      string
+	Interval     int
+	ThenComplete bool
+	ThenRun      string
+
+	// Flags for 'pr requeue'
+	Policy string
+	Wait   bool
+
+	// identity is the user confirmed by confirmIdentity, once per command
+	identity          *utils.Identity
+	identityConfirmed bool
+}
+
+// confirmIdentity confirms who acts on the PR, see utils.ConfirmIdentity.
+// Commands that chain actions (pr wait --then-complete) only ask once.
+func (p *PrhandlerCmd) confirmIdentity(orgURL string) *utils.Identity {
+	if p.identityConfirmed {
+		return p.identity
+	}
+	identity, err := utils.ConfirmIdentity(p.PAT, orgURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	p.identity, p.identityConfirmed = identity, true
+	return identity
+}
+
+// prVote describes one of the vote actions of 'pr'
+type prVote struct {
+	azVote       string
+	action       string
+	threadStatus string
+}
+
+// prVotes maps vote flags to the az set-vote value, a description of the action
+// and the status of the thread created for a comment posted with the vote.
+// ADO vote values: 10 = approved, 5 = approved with suggestions, 0 = no vote, -5 = waiting for author, -10 = rejected
+var prVotes = map[string]prVote{
+	"--approve":                  {"approve", "approved", "closed"},
+	"--approve-with-suggestions": {"approve-with-suggestions", "approved with suggestions", "active"},
+	"--wait-for-author":          {"wait-for-author", "marked as waiting for author", "active"},
+	"--reject":                   {"reject", "rejected", "active"},
+	"--reset":                    {"reset", "vote reset", "closed"},
+}
+
+// prSubcommands lists the words that select a 'pr' subcommand instead of a vote
+var prSubcommands = map[string]bool{
+	"update":     true,
+	"complete":   true,
+	"abandon":    true,
+	"reactivate": true,
+	"list":       true,
+	"comments":   true,
+	"comment":    true,
+	"reply":      true,
+	"resolve":    true,
+	"diff":       true,
+	"checkout":   true,
+	"status":     true,
+	"wait":       true,
+	"requeue":    true,
 }
 
 // parsePRUrl parses Azure DevOps PR URL and extracts components
 // Supports:
 // - https://dev.azure.com/{org}/{project}/_git/{repo}/pullrequest/{pr_id}
 // - https://{org}.visualstudio.com/{project}/_git/{repo}/pullrequest/{pr_id}
-func parsePRUrl(rawURL string) (project, repository string, prID string, err error) {
-	parsed, err := url.Parse(rawURL)
+// When the project segment is missing, the repository name is used as the project.
+func parsePRUrl(rawURL string) (orgURL, project, repository string, prID string, err error) {
+	orgURL, pathParts, _, err := utils.ResolveADOUrl(rawURL)
 	if err != nil {
-		return "", "", "", err
+		return "", "", "", "", err
 	}
 
-	pathParts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
-
 	// Find indices for _git and pullrequest
 	gitIndex := -1
 	prIndex := -1
@@ -67,128 +214,366 @@ func parsePRUrl(rawURL string) (project, repository string, prID string, err err
 		}
 	}
 
-	if gitIndex == -1 || prIndex == -1 || gitIndex < 1 || prIndex+1 >= len(pathParts) {
-		return "", "", "", fmt.Errorf("invalid PR URL format")
+	if gitIndex == -1 || prIndex == -1 || gitIndex+1 >= len(pathParts) || prIndex+1 >= len(pathParts) {
+		return "", "", "", "", fmt.Errorf("invalid PR URL format")
 	}
 
-	// Project is the part right before _git
-	project = pathParts[gitIndex-1]
 	repository = pathParts[gitIndex+1]
 	prID = pathParts[prIndex+1]
 
-	return project, repository, prID, nil
+	// Project is the part right before _git
+	project = repository
+	if gitIndex > 0 {
+		project = pathParts[gitIndex-1]
+	}
+
+	return orgURL, project, repository, prID, nil
 }
 
 func (p *PrhandlerCmd) Run() {
-	// Validate that exactly one action is specified
-	if p.Approve == p.Reset {
-		fmt.Fprintln(os.Stderr, "Error: You must specify either --approve or --reset (but not both)")
-		fmt.Println(prhandlerHelp)
-		os.Exit(1)
+	switch p.Subcommand {
+	case "update":
+		p.updatePR()
+	case "complete":
+		p.completePR()
+	case "abandon":
+		p.setPRStatus("abandoned", "abandoned")
+	case "reactivate":
+		p.setPRStatus("active", "reactivated")
+	case "list":
+		p.listPullRequests()
+	case "comments":
+		p.listComments()
+	case "comment":
+		p.addComment()
+	case "reply":
+		p.replyToThread()
+	case "resolve":
+		p.markThreadResolved()
+	case "diff":
+		p.diffPR()
+	case "checkout":
+		p.checkoutPR()
+	case "status":
+		p.showStatus()
+	case "wait":
+		p.waitForPR()
+	case "requeue":
+		p.requeuePolicies()
+	default:
+		p.vote()
 	}
+}
 
-	if p.PRURL == "" {
-		fmt.Fprintln(os.Stderr, "Error: PR URL is required")
-		fmt.Println(prhandlerHelp)
+func (p *PrhandlerCmd) vote() {
+	// Validate that exactly one action is specified
+	if len(p.Votes) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: You must specify exactly one of --approve, --approve-with-suggestions, --wait-for-author, --reject or --reset")
+		fmt.Print(prhandlerHelp)
 		os.Exit(1)
 	}
 
 	// Get PAT (optional - if not provided, will use az login identity)
 	pat := utils.GetPAT(p.PAT)
 
-	project, repository, prID, err := parsePRUrl(p.PRURL)
+	vote := prVotes[p.Votes[0]]
+
+	refs, err := p.voteTargets(pat)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing PR URL: %s\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if len(refs) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: PR URL is required")
+		fmt.Print(prhandlerHelp)
 		os.Exit(1)
 	}
 
-	// Get organization from config
-	orgURL := utils.GetOrganization("")
+	if len(refs) > 1 {
+		p.bulkVote(pat, refs, vote)
+		return
+	}
 
-	// Determine vote value
-	// Vote values: 10 = approved, 5 = approved with suggestions, 0 = no vote, -5 = waiting for author, -10 = rejected
-	var vote string
-	var action string
-	if p.Approve {
-		vote = "approve"
-		action = "approved"
-	} else {
-		vote = "reset"
-		action = "vote reset"
+	// A PR URL is looked up in its own project and repository, so a PR ID
+	// from another repository is never voted on by mistake
+	pr, orgURL, err := resolvePR(pat, refs[0], "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
 	}
 
+	identity, err := utils.ConfirmIdentity(p.PAT, orgURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	prID := strconv.Itoa(pr.PullRequestID)
+	project := pr.Repository.Project.Name
+	repository := pr.Repository.Name
+
 	fmt.Printf("Processing PR #%s...\n", prID)
 	fmt.Printf("Organization: %s\n", orgURL)
 	fmt.Printf("Project: %s\n", project)
 	fmt.Printf("Repository: %s\n", repository)
-	fmt.Printf("Action: %s\n", action)
-
-	// Use az repos pr set-vote command
-	// If PAT is provided, use it; otherwise rely on az login
-	var stdout, stderr string
-	if pat != "" {
-		stdout, stderr, err = utils.RunCommandWithPAT(pat, "az", "repos", "pr", "set-vote",
-			"--id", prID,
-			"--vote", vote,
-			"--org", orgURL,
-			"-o", "json",
-		)
-	} else {
-		stdout, stderr, err = utils.RunCommand("az", "repos", "pr", "set-vote",
-			"--id", prID,
-			"--vote", vote,
-			"--org", orgURL,
-			"-o", "json",
-		)
-	}
+	fmt.Printf("Action: %s\n", vote.action)
 
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", stderr)
+	if err := setVote(pat, orgURL, prID, vote); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
 	}
+	auditAction(identity, p.PAT != "", prWebURL(pr), "vote "+vote.azVote)
+
+	fmt.Printf("✓ PR #%s %s successfully!\n", prID, vote.action)
+	fmt.Printf("  Repository: %s\n", repository)
+	fmt.Printf("  Project: %s\n", project)
+
+	if p.Comment != "" {
+		if err := postPRComment(pat, pr, p.Comment, vote.threadStatus); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Vote recorded but comment was not posted: %s\n", err)
+		} else {
+			fmt.Println("  ✓ Comment posted")
+		}
+	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
-		// Command succeeded but response parsing failed - still a success
-		fmt.Printf("✓ PR #%s %s successfully!\n", prID, action)
-		fmt.Printf("  Repository: %s\n", repository)
-		fmt.Printf("  Project: %s\n", project)
+	reviewers, err := listReviewers(pat, orgURL, prID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not list reviewers: %s\n", err)
 		return
 	}
+	fmt.Println()
+	printReviewers(reviewers)
+}
 
-	fmt.Printf("✓ PR #%s %s successfully!\n", prID, action)
-	fmt.Printf("  Repository: %s\n", repository)
-	fmt.Printf("  Project: %s\n", project)
+// setVote records a vote on a PR with az repos pr set-vote
+// If PAT is provided, use it; otherwise rely on az login
+func setVote(pat, orgURL, prID string, vote prVote) error {
+	_, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "repos", "pr", "set-vote",
+		"--id", prID,
+		"--vote", vote.azVote,
+		"--org", orgURL,
+		"-o", "json",
+	)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(stderr))
+	}
+	return nil
 }
 
 // ParsePrhandlerArgs parses command line arguments for prhandler command
 func ParsePrhandlerArgs(args []string) *PrhandlerCmd {
 	cmd := &PrhandlerCmd{}
 
+	if len(args) > 0 && prSubcommands[args[0]] {
+		cmd.Subcommand = args[0]
+		args = args[1:]
+	}
+
 	positionalArgs := []string{}
 
+	// nextValue returns the value following flag i, advancing the index
+	nextValue := func(i *int) string {
+		if *i+1 < len(args) {
+			*i++
+			return args[*i]
+		}
+		return ""
+	}
+
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		switch {
-		case arg == "--approve":
-			cmd.Approve = true
-		case arg == "--reset":
-			cmd.Reset = true
-		case arg == "-t" || arg == "--token":
-			if i+1 < len(args) {
-				i++
-				cmd.PAT = args[i]
+		case arg == "--":
+			// Everything after -- is positional, e.g. comment text starting with '-'
+			positionalArgs = append(positionalArgs, args[i+1:]...)
+			i = len(args)
+		case arg == "--title":
+			cmd.Title = nextValue(&i)
+		case strings.HasPrefix(arg, "--title="):
+			cmd.Title = strings.TrimPrefix(arg, "--title=")
+		case arg == "-d" || arg == "--description":
+			cmd.Description = nextValue(&i)
+		case strings.HasPrefix(arg, "--description="):
+			cmd.Description = strings.TrimPrefix(arg, "--description=")
+		case arg == "-b" || arg == "--target":
+			cmd.Target = nextValue(&i)
+		case strings.HasPrefix(arg, "--target="):
+			cmd.Target = strings.TrimPrefix(arg, "--target=")
+		case arg == "--draft":
+			cmd.Draft = "true"
+		case arg == "--publish":
+			cmd.Draft = "false"
+		case arg == "--auto-complete":
+			cmd.AutoComplete = "true"
+		case arg == "--cancel-auto-complete":
+			cmd.AutoComplete = "false"
+		case arg == "--squash":
+			cmd.Squash = "true"
+		case arg == "--no-squash":
+			cmd.Squash = "false"
+		case arg == "--delete-source-branch":
+			cmd.DeleteSourceBranch = "true"
+		case arg == "--keep-source-branch":
+			cmd.DeleteSourceBranch = "false"
+		case arg == "--merge-message":
+			cmd.MergeMessage = nextValue(&i)
+		case strings.HasPrefix(arg, "--merge-message="):
+			cmd.MergeMessage = strings.TrimPrefix(arg, "--merge-message=")
+		case arg == "--mine":
+			cmd.Mine = true
+		case arg == "--author":
+			cmd.Author = nextValue(&i)
+		case strings.HasPrefix(arg, "--author="):
+			cmd.Author = strings.TrimPrefix(arg, "--author=")
+		case arg == "--passing-builds":
+			cmd.PassingBuilds = true
+		case arg == "-p" || arg == "--parallel":
+			if val, err := strconv.Atoi(nextValue(&i)); err == nil {
+				cmd.Parallel = val
+			}
+		case strings.HasPrefix(arg, "--parallel="):
+			if val, err := strconv.Atoi(strings.TrimPrefix(arg, "--parallel=")); err == nil {
+				cmd.Parallel = val
 			}
+		case arg == "-f" || arg == "--force":
+			utils.Force = true
+		case arg == "--reviewing":
+			cmd.Reviewing = true
+		case arg == "--waiting-on-me":
+			cmd.WaitingOnMe = true
+		case arg == "--repo":
+			cmd.Repo = nextValue(&i)
+		case strings.HasPrefix(arg, "--repo="):
+			cmd.Repo = strings.TrimPrefix(arg, "--repo=")
+		case arg == "--status":
+			cmd.Status = nextValue(&i)
+		case strings.HasPrefix(arg, "--status="):
+			cmd.Status = strings.TrimPrefix(arg, "--status=")
+		case arg == "--sort":
+			cmd.Sort = nextValue(&i)
+		case strings.HasPrefix(arg, "--sort="):
+			cmd.Sort = strings.TrimPrefix(arg, "--sort=")
+		case arg == "--top":
+			if val, err := strconv.Atoi(nextValue(&i)); err == nil {
+				cmd.Top = val
+			}
+		case strings.HasPrefix(arg, "--top="):
+			if val, err := strconv.Atoi(strings.TrimPrefix(arg, "--top=")); err == nil {
+				cmd.Top = val
+			}
+		case arg == "--file":
+			cmd.File = nextValue(&i)
+		case strings.HasPrefix(arg, "--file="):
+			cmd.File = strings.TrimPrefix(arg, "--file=")
+		case arg == "--line":
+			if val, err := strconv.Atoi(nextValue(&i)); err == nil {
+				cmd.Line = val
+			}
+		case strings.HasPrefix(arg, "--line="):
+			if val, err := strconv.Atoi(strings.TrimPrefix(arg, "--line=")); err == nil {
+				cmd.Line = val
+			}
+		case arg == "--pr":
+			cmd.ThreadPR = nextValue(&i)
+		case strings.HasPrefix(arg, "--pr="):
+			cmd.ThreadPR = strings.TrimPrefix(arg, "--pr=")
+		case arg == "--all":
+			cmd.ShowAll = true
+		case arg == "--name-only":
+			cmd.NameOnly = true
+		case arg == "--until":
+			cmd.Until = nextValue(&i)
+		case strings.HasPrefix(arg, "--until="):
+			cmd.Until = strings.TrimPrefix(arg, "--until=")
+		case arg == "--timeout":
+			cmd.Timeout = nextValue(&i)
+		case strings.HasPrefix(arg, "--timeout="):
+			cmd.Timeout = strings.TrimPrefix(arg, "--timeout=")
+		case arg == "--interval":
+			if val, err := strconv.Atoi(nextValue(&i)); err == nil {
+				cmd.Interval = val
+			}
+		case strings.HasPrefix(arg, "--interval="):
+			if val, err := strconv.Atoi(strings.TrimPrefix(arg, "--interval=")); err == nil {
+				cmd.Interval = val
+			}
+		case arg == "--policy":
+			cmd.Policy = nextValue(&i)
+		case strings.HasPrefix(arg, "--policy="):
+			cmd.Policy = strings.TrimPrefix(arg, "--policy=")
+		case arg == "--wait":
+			cmd.Wait = true
+		case arg == "--then-complete":
+			cmd.ThenComplete = true
+		case arg == "--then-run":
+			cmd.ThenRun = nextValue(&i)
+		case strings.HasPrefix(arg, "--then-run="):
+			cmd.ThenRun = strings.TrimPrefix(arg, "--then-run=")
+		case arg == "--merge-strategy":
+			cmd.MergeStrategy = nextValue(&i)
+		case strings.HasPrefix(arg, "--merge-strategy="):
+			cmd.MergeStrategy = strings.TrimPrefix(arg, "--merge-strategy=")
+		case arg == "--bypass-policy":
+			cmd.BypassPolicy = true
+		case arg == "--bypass-reason":
+			cmd.BypassReason = nextValue(&i)
+		case strings.HasPrefix(arg, "--bypass-reason="):
+			cmd.BypassReason = strings.TrimPrefix(arg, "--bypass-reason=")
+		case arg == "--add-reviewer":
+			cmd.AddReviewers = append(cmd.AddReviewers, nextValue(&i))
+		case strings.HasPrefix(arg, "--add-reviewer="):
+			cmd.AddReviewers = append(cmd.AddReviewers, strings.TrimPrefix(arg, "--add-reviewer="))
+		case arg == "--remove-reviewer":
+			cmd.RemoveReviewers = append(cmd.RemoveReviewers, nextValue(&i))
+		case strings.HasPrefix(arg, "--remove-reviewer="):
+			cmd.RemoveReviewers = append(cmd.RemoveReviewers, strings.TrimPrefix(arg, "--remove-reviewer="))
+		case arg == "-i" || arg == "--work-item":
+			cmd.WorkItems = append(cmd.WorkItems, nextValue(&i))
+		case strings.HasPrefix(arg, "--work-item="):
+			cmd.WorkItems = append(cmd.WorkItems, strings.TrimPrefix(arg, "--work-item="))
+		case prVotes[arg].azVote != "":
+			cmd.Votes = append(cmd.Votes, arg)
+		case arg == "-m" || arg == "--comment":
+			cmd.Comment = nextValue(&i)
+		case strings.HasPrefix(arg, "--comment="):
+			cmd.Comment = strings.TrimPrefix(arg, "--comment=")
+		case arg == "-t" || arg == "--token":
+			cmd.PAT = nextValue(&i)
 		case strings.HasPrefix(arg, "--token="):
 			cmd.PAT = strings.TrimPrefix(arg, "--token=")
 		case strings.HasPrefix(arg, "-t="):
 			cmd.PAT = strings.TrimPrefix(arg, "-t=")
 		case arg == "-h" || arg == "--help":
-			fmt.Println(prhandlerHelp)
+			switch cmd.Subcommand {
+			case "update":
+				fmt.Print(prUpdateHelp)
+			case "complete", "abandon", "reactivate":
+				fmt.Print(prCompleteHelp)
+			case "list":
+				fmt.Print(prListHelp)
+			case "comments", "comment", "reply", "resolve":
+				fmt.Print(prCommentsHelp)
+			case "diff", "checkout":
+				fmt.Print(prDiffHelp)
+			case "status":
+				fmt.Print(prStatusHelp)
+			case "wait":
+				fmt.Print(prWaitHelp)
+			case "requeue":
+				fmt.Print(prRequeueHelp)
+			default:
+				fmt.Print(prhandlerHelp)
+			}
 			os.Exit(0)
+		case arg == "-":
+			// Read PR URLs from stdin
+			positionalArgs = append(positionalArgs, arg)
 		default:
 			if !strings.HasPrefix(arg, "-") {
 				positionalArgs = append(positionalArgs, arg)
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: Ignoring unknown flag '%s' (put text starting with '-' after --)\n", arg)
 			}
 		}
 	}
@@ -197,6 +582,7 @@ func ParsePrhandlerArgs(args []string) *PrhandlerCmd {
 	if len(positionalArgs) >= 1 {
 		cmd.PRURL = positionalArgs[0]
 	}
+	cmd.PRURLs = positionalArgs
 
 	return cmd
 }
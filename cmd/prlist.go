@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+const prListHelp = `pr list - List and filter Pull Requests
+
+USAGE:
+  defenders pr list [flags]
+
+FILTERS:
+  --mine                 PRs you created
+  --author <user>        PRs created by this user
+  --reviewing            PRs where you are a reviewer
+  --waiting-on-me        PRs where your vote is "no vote" or "waiting for author"
+  --repo <name>          Only PRs in this repository (default: all repositories in the project)
+  --status <status>      active, completed, abandoned or all (default: active)
+  -b, --target <branch>  Only PRs targeting this branch
+
+FLAGS:
+  --sort <order>         newest or oldest (default: newest)
+  --top <n>              Maximum number of PRs to show (default: 50)
+  -t, --token            Personal Access Token (overrides config/env)
+
+Uses the organization and project from 'defenders conf'.
+
+EXAMPLES:
+  defenders pr list --waiting-on-me
+  defenders pr list --mine --status completed --top 10
+  defenders pr list --repo MyRepo -b develop --sort oldest
+`
+
+// prListStatuses are the values accepted by --status
+var prListStatuses = map[string]bool{
+	"active":    true,
+	"completed": true,
+	"abandoned": true,
+	"all":       true,
+}
+
+func (p *PrhandlerCmd) listPullRequests() {
+	pat := utils.GetPAT(p.PAT)
+
+	prs, err := p.queryPRs(pat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if len(prs) == 0 {
+		fmt.Println("No pull requests found.")
+		return
+	}
+
+	evaluations := fetchPolicies(pat, prs)
+
+	for i, pr := range prs {
+		draft := ""
+		if pr.IsDraft {
+			draft = " [draft]"
+		}
+		fmt.Printf("#%d  %s%s\n", pr.PullRequestID, pr.Title, draft)
+		fmt.Printf("      %s | %s -> %s | %s old\n", pr.CreatedBy.DisplayName,
+			branchName(pr.SourceRefName), branchName(pr.TargetRefName), formatAge(pr.CreationDate))
+		policies := "unknown"
+		if evaluations[i] != nil {
+			policies = policySummary(evaluations[i])
+		}
+		fmt.Printf("      Votes: %s | Policies: %s\n", voteSummary(pr.Reviewers), policies)
+		fmt.Printf("      %s\n\n", prWebURL(&pr))
+	}
+	fmt.Printf("%d pull request(s)\n", len(prs))
+}
+
+// queryPRs returns the PRs in the configured project that match the list filters
+func (p *PrhandlerCmd) queryPRs(pat string) ([]pullRequest, error) {
+	orgURL := utils.GetOrganization("")
+	project := utils.GetProject("")
+
+	status := p.Status
+	if status == "" {
+		status = "active"
+	}
+	if !prListStatuses[status] {
+		return nil, fmt.Errorf("unknown status '%s' (use active, completed, abandoned or all)", status)
+	}
+
+	top := p.Top
+	if top <= 0 {
+		top = 50
+	}
+
+	switch p.Sort {
+	case "", "newest", "oldest":
+	default:
+		return nil, fmt.Errorf("unknown sort order '%s' (use newest or oldest)", p.Sort)
+	}
+
+	args := []string{
+		"--org", orgURL,
+		"--project", project,
+		"--status", status,
+	}
+	if p.Repo != "" {
+		args = append(args, "--repository", p.Repo)
+	}
+	if p.Target != "" {
+		args = append(args, "--target-branch", p.Target)
+	}
+
+	var me *utils.Identity
+	if p.Mine || p.Reviewing || p.WaitingOnMe {
+		identity, err := utils.GetIdentity(pat, orgURL)
+		if err != nil {
+			return nil, err
+		}
+		me = identity
+	}
+	if p.Mine {
+		args = append(args, "--creator", me.Email)
+	} else if p.Author != "" {
+		args = append(args, "--creator", p.Author)
+	}
+	if p.Reviewing || p.WaitingOnMe {
+		args = append(args, "--reviewer", me.Email)
+	}
+
+	// ADO returns the newest PRs first. Sorting by oldest or filtering on the
+	// client needs every PR, --top is applied afterwards.
+	var prs []pullRequest
+	if p.Sort == "oldest" || p.WaitingOnMe {
+		all, err := listAllPRs(pat, args...)
+		if err != nil {
+			return nil, err
+		}
+		prs = all
+	} else {
+		page, err := listPRs(pat, append(args, "--top", strconv.Itoa(top))...)
+		if err != nil {
+			return nil, err
+		}
+		prs = page
+	}
+
+	if p.WaitingOnMe {
+		var waiting []pullRequest
+		for _, pr := range prs {
+			for _, r := range pr.Reviewers {
+				if r.ID == me.ID && (r.Vote == 0 || r.Vote == -5) {
+					waiting = append(waiting, pr)
+					break
+				}
+			}
+		}
+		prs = waiting
+	}
+
+	if p.Sort == "oldest" {
+		sort.SliceStable(prs, func(i, j int) bool { return prs[i].CreationDate.Before(prs[j].CreationDate) })
+	} else {
+		sort.SliceStable(prs, func(i, j int) bool { return prs[i].CreationDate.After(prs[j].CreationDate) })
+	}
+
+	if len(prs) > top {
+		prs = prs[:top]
+	}
+	return prs, nil
+}
+
+// prListPageSize is the number of PRs requested per call when listing every PR
+const prListPageSize = 100
+
+// listAllPRs runs 'az repos pr list' page by page until every matching PR is returned
+func listAllPRs(pat string, args ...string) ([]pullRequest, error) {
+	var prs []pullRequest
+	for skip := 0; ; skip += prListPageSize {
+		pageArgs := append(append([]string{}, args...),
+			"--top", strconv.Itoa(prListPageSize),
+			"--skip", strconv.Itoa(skip),
+		)
+		page, err := listPRs(pat, pageArgs...)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, page...)
+		if len(page) < prListPageSize {
+			return prs, nil
+		}
+	}
+}
+
+// fetchPolicies returns the policy evaluations of every PR, fetched concurrently
+// since each PR needs its own call. Entries are nil when the lookup failed.
+func fetchPolicies(pat string, prs []pullRequest) [][]policyEvaluation {
+	evaluations := make([][]policyEvaluation, len(prs))
+	utils.RunParallel(8, len(prs), func(i int) {
+		evals, err := listPolicies(pat, prs[i].org(), strconv.Itoa(prs[i].PullRequestID))
+		if err == nil {
+			evaluations[i] = evals
+		}
+	})
+	return evaluations
+}
+
+// formatAge returns a short human readable age, e.g. "3d", "5h" or "12m"
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	}
+}
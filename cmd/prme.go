@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -17,18 +16,34 @@ USAGE:
 FLAGS:
   -i, --work-item  Work item ID to link to the PR
   -t, --title      Custom PR title (default: branch name after last /)
+  -b, --target     Target branch (default: stacked parent branch, else develop/main/master)
+  -f, --force      Push without asking when the branch is not up to date on origin
   -h, --help       Show this help message
 
+TARGET BRANCH:
+  Without --target, prme looks for the nearest branch on origin that the
+  current branch was created from (stacked work). If none is found, the
+  default branch (develop, main or master) is used.
+
+PRE-FLIGHT CHECKS:
+  - Warns about uncommitted changes (they are not part of the PR)
+  - Offers to push the branch when it has no upstream or unpushed commits
+  - Refuses to open a PR with no commits ahead of the target branch
+  - Prints the existing active PR for the same source and target instead
+    of creating a new one
+
 EXAMPLES:
   defenders prme
   defenders prme -i 12345
   defenders prme -t "My PR Title"
   defenders prme -i 12345 -t "My PR Title"
+  defenders prme -b release/2024.10
 `
 
 type PrmeCmd struct {
 	WorkItem string
 	Title    string
+	Target   string
 }
 
 func (p *PrmeCmd) Run() {
@@ -44,12 +59,48 @@ func (p *PrmeCmd) Run() {
 		os.Exit(1)
 	}
 
-	// Determine default branch
-	defaultBranch, err := utils.GetDefaultBranch()
+	// Determine target branch
+	target := p.Target
+	if target == "" {
+		target, err = detectTargetBranch(branch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Pre-flight checks before talking to ADO
+	if dirty, err := utils.HasUncommittedChanges(); err == nil && dirty {
+		fmt.Println("Warning: You have uncommitted changes - they will not be part of the PR")
+	}
+
+	existing, err := findActivePR("", branch, target)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
 	}
+	if existing != nil {
+		fmt.Printf("An active PR already exists for %s -> %s:\n", branch, target)
+		fmt.Printf("  #%d %s\n", existing.PullRequestID, existing.Title)
+		fmt.Println(prWebURL(existing))
+		return
+	}
+
+	ahead, err := utils.CountCommits("origin/"+target, branch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Target branch %s not found on origin\n", target)
+		os.Exit(1)
+	}
+	if ahead == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s has no commits ahead of %s - nothing to review\n", branch, target)
+		os.Exit(1)
+	}
+
+	// Push last, once it is certain that a PR will be created
+	if err := ensureBranchPushed(branch); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
 
 	// Set title (custom or default from branch name)
 	title := p.Title
@@ -57,58 +108,72 @@ func (p *PrmeCmd) Run() {
 		title = utils.GetBranchTitle(branch)
 	}
 
-	fmt.Printf("Creating PR: %s -> %s\n", branch, defaultBranch)
+	fmt.Printf("Creating PR: %s -> %s\n", branch, target)
 	fmt.Printf("Title: %s\n", title)
 	if p.WorkItem != "" {
 		fmt.Printf("Work Item: %s\n", p.WorkItem)
 	}
 
-	// Build az command arguments
-	args := []string{"repos", "pr", "create",
-		"-s", branch,
-		"-t", defaultBranch,
-		"--title", title,
-		"-o", "json",
+	pr, err := createPR("", branch, target, title, p.WorkItem)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
 	}
 
-	if p.WorkItem != "" {
-		args = append(args, "--work-items", p.WorkItem)
+	if pr.Repository.Name != "" && pr.PullRequestID != 0 {
+		fmt.Println(prWebURL(pr))
+	} else {
+		fmt.Println("PR created successfully")
 	}
+}
 
-	// Execute az command
-	stdout, stderr, err := utils.RunCommand("az", args...)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to create PR:")
-		fmt.Fprintln(os.Stderr, stderr)
-		os.Exit(1)
+// ensureBranchPushed makes sure branch exists on origin with all local commits
+// It asks before pushing unless utils.Force is set. A branch missing from origin
+// is an error when the push is declined, since ADO cannot open a PR for it.
+func ensureBranchPushed(branch string) error {
+	upstream := utils.GetUpstream(branch)
+	if upstream == "" {
+		fmt.Printf("Branch %s has no upstream on origin.\n", branch)
+		if !utils.AskUser("Push %s to origin? [y/N]: ", branch) {
+			return fmt.Errorf("branch %s must be pushed before opening a PR", branch)
+		}
+		return utils.PushBranch(branch)
 	}
 
-	// Parse JSON response
-	var prJSON map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &prJSON); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing response: %s\n", err)
-		os.Exit(1)
+	unpushed, err := utils.CountCommits(upstream, branch)
+	if err != nil {
+		return err
 	}
-
-	// Extract repository name and PR ID
-	repoName := ""
-	if repo, ok := prJSON["repository"].(map[string]interface{}); ok {
-		repoName = repo["name"].(string)
+	if unpushed > 0 {
+		fmt.Printf("Branch %s has %d unpushed commit(s).\n", branch, unpushed)
+		if !utils.AskUser("Push %s to origin? [y/N]: ", branch) {
+			fmt.Println("Warning: The PR will not include unpushed commits")
+			return nil
+		}
+		return utils.PushBranch(branch)
 	}
 
-	prID := ""
-	if id, ok := prJSON["pullRequestId"].(float64); ok {
-		prID = fmt.Sprintf("%.0f", id)
+	return nil
+}
+
+// detectTargetBranch returns the stacked parent of branch, falling back to the default branch
+func detectTargetBranch(branch string) (string, error) {
+	defaultBranch, err := utils.GetDefaultBranch()
+	if err != nil {
+		return "", err
 	}
 
-	if repoName != "" && prID != "" {
-		org := utils.GetOrganization("")
-		project := utils.GetProject("")
-		fmt.Printf("%s/%s/_git/%s/pullrequest/%s\n", org, project, repoName, prID)
-	} else {
-		fmt.Println("PR created successfully")
-		fmt.Println(stdout)
+	parent, err := utils.FindStackParent(branch, defaultBranch)
+	if err != nil {
+		// Stack detection is best effort - the default branch is always a valid target
+		return defaultBranch, nil
 	}
+	if parent != "" {
+		fmt.Printf("Detected stacked parent branch: %s\n", parent)
+		return parent, nil
+	}
+
+	return defaultBranch, nil
 }
 
 // ParsePrmeArgs parses command line arguments for prme command
@@ -128,12 +193,21 @@ func ParsePrmeArgs(args []string) *PrmeCmd {
 				i++
 				cmd.Title = args[i]
 			}
+		case arg == "-b" || arg == "--target":
+			if i+1 < len(args) {
+				i++
+				cmd.Target = args[i]
+			}
 		case strings.HasPrefix(arg, "--work-item="):
 			cmd.WorkItem = strings.TrimPrefix(arg, "--work-item=")
 		case strings.HasPrefix(arg, "--title="):
 			cmd.Title = strings.TrimPrefix(arg, "--title=")
+		case strings.HasPrefix(arg, "--target="):
+			cmd.Target = strings.TrimPrefix(arg, "--target=")
+		case arg == "-f" || arg == "--force":
+			utils.Force = true
 		case arg == "-h" || arg == "--help":
-			fmt.Println(prmeHelp)
+			fmt.Print(prmeHelp)
 			os.Exit(0)
 		}
 	}
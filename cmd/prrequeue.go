@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+const prRequeueHelp = `pr requeue - Re-queue PR validation builds
+
+USAGE:
+  defenders pr requeue [<pr-url>|<pr-id>] [--policy <name>] [--wait [--timeout <duration>]]
+
+Re-evaluates the build policies of a PR that expired or failed. With --policy,
+only the named policy is re-queued, whatever its current state.
+
+FLAGS:
+  --policy <name>       Policy to re-queue (display name, see 'pr status')
+  --wait                Wait for the new validation builds to finish and show
+                        their links (without --wait, follow them with 'pr status')
+  --timeout <duration>  Give up waiting after this long, e.g. 30m or 2h (default: no timeout)
+  --interval <seconds>  Check interval in seconds when waiting (default: 30)
+  -t, --token           Personal Access Token (overrides config/env)
+
+Without a PR URL or ID, the active PR of the current branch is used.
+Exits with 1 when a waited-for build does not succeed, the timeout is reached or
+the policies or builds cannot be checked 5 times in a row.
+
+EXAMPLES:
+  defenders pr requeue <url>
+  defenders pr requeue <url> --policy "CI Build" --wait --timeout 1h
+`
+
+func (p *PrhandlerCmd) requeuePolicies() {
+	var timeout time.Duration
+	if p.Timeout != "" {
+		if !p.Wait {
+			fmt.Fprintln(os.Stderr, "Error: --timeout requires --wait")
+			os.Exit(1)
+		}
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%s' (e.g. 30m or 2h)\n", p.Timeout)
+			os.Exit(1)
+		}
+		timeout = d
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	pr, orgURL, err := resolvePR(pat, p.PRURL, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if pr.Status != "active" {
+		fmt.Fprintf(os.Stderr, "Error: PR #%d is %s\n", pr.PullRequestID, pr.Status)
+		os.Exit(1)
+	}
+
+	identity := p.confirmIdentity(orgURL)
+
+	prID := strconv.Itoa(pr.PullRequestID)
+	project := pr.Repository.Project.Name
+
+	policies, err := listPolicies(pat, orgURL, prID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get policies: %s\n", err)
+		os.Exit(1)
+	}
+
+	var selected []policyEvaluation
+	for _, e := range policies {
+		if !e.Configuration.IsEnabled {
+			continue
+		}
+		if p.Policy != "" {
+			if strings.EqualFold(e.name(), p.Policy) {
+				selected = append(selected, e)
+			}
+			continue
+		}
+		if e.Configuration.Type.DisplayName == "Build" &&
+			(e.Context.IsExpired || e.Status == "rejected" || e.Status == "broken") {
+			selected = append(selected, e)
+		}
+	}
+
+	if len(selected) == 0 {
+		if p.Policy != "" {
+			fmt.Fprintf(os.Stderr, "Error: PR #%s has no policy named '%s'\n", prID, p.Policy)
+			os.Exit(1)
+		}
+		fmt.Printf("PR #%s has no expired or failed build policies.\n", prID)
+		return
+	}
+
+	fmt.Printf("Re-queuing policies of PR #%s: %s\n\n", prID, pr.Title)
+
+	// The previous build of each policy, to recognize the new one when waiting
+	previous := make(map[string]int)
+	failed := 0
+	for _, e := range selected {
+		previous[e.EvaluationID] = e.Context.BuildID
+		if err := queuePolicy(pat, orgURL, prID, e.EvaluationID); err != nil {
+			failed++
+			fmt.Printf("  ✗ %s\n      %s\n", e.name(), err)
+			continue
+		}
+		auditAction(identity, p.PAT != "", prWebURL(pr), "requeue policy "+e.name())
+		fmt.Printf("  ✓ %s re-queued\n", e.name())
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+
+	if !p.Wait {
+		// The new builds are only known once ADO has started them
+		fmt.Printf("\nThe links of the new builds are shown with --wait.\n")
+		fmt.Printf("Run 'defenders pr status %s' to follow the builds.\n", prWebURL(pr))
+		return
+	}
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 30
+	}
+
+	// One deadline covers both finding the new builds and waiting for them
+	deadline := time.Now().Add(timeout)
+
+	fmt.Println("\nWaiting for the new builds to start...")
+	builds, err := newPolicyBuilds(pat, orgURL, prID, previous, interval, timeout)
+	if errors.Is(err, errBuildTimeout) {
+		fmt.Fprintf(os.Stderr, "\nError: Timed out after %s waiting for the builds to start\n", timeout)
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "\nError: %s\n", err)
+		os.Exit(1)
+	}
+
+	for _, buildID := range builds {
+		fmt.Printf("\nBuild: %s\n", buildWebURL(orgURL, project, buildID))
+		remaining := time.Duration(0)
+		if timeout > 0 {
+			if remaining = time.Until(deadline); remaining <= 0 {
+				fmt.Fprintf(os.Stderr, "\nError: Timed out after %s waiting for the builds\n", timeout)
+				os.Exit(1)
+			}
+		}
+		build, err := waitForBuild(pat, orgURL, project, buildID, interval, remaining, prCheckRetries)
+		if errors.Is(err, errBuildTimeout) {
+			fmt.Fprintf(os.Stderr, "\nError: Timed out after %s waiting for the builds\n", timeout)
+			os.Exit(1)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "\nError: %s\n", err)
+			os.Exit(1)
+		}
+		if build == nil || build.Result != "succeeded" {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "\nError: %d of %d builds did not succeed\n", failed, len(builds))
+		os.Exit(1)
+	}
+	fmt.Println("\n✓ All re-queued builds succeeded")
+}
+
+// prCheckRetries is the number of failed PR, policy or build checks in a row
+// after which pr wait and pr requeue give up
+const prCheckRetries = 5
+
+// newPolicyBuilds polls the PR policies until every re-queued build policy
+// references a new build, and returns the IDs of those builds.
+// Policies that do not run builds are not waited for. A timeout of 0 waits forever.
+func newPolicyBuilds(pat, orgURL, prID string, previous map[string]int, interval int, timeout time.Duration) ([]int, error) {
+	deadline := time.Now().Add(timeout)
+	failures := 0
+	for {
+		if timeout > 0 && time.Now().Add(time.Duration(interval)*time.Second).After(deadline) {
+			return nil, errBuildTimeout
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+
+		policies, err := listPolicies(pat, orgURL, prID)
+		if err != nil {
+			failures++
+			if failures >= prCheckRetries {
+				return nil, fmt.Errorf("giving up after %d failed policy checks in a row: %w", failures, err)
+			}
+			fmt.Fprintf(os.Stderr, "Error checking policies (%d/%d): %s\n", failures, prCheckRetries, err)
+			continue
+		}
+		failures = 0
+
+		var builds []int
+		pending := 0
+		for _, e := range policies {
+			oldBuild, ok := previous[e.EvaluationID]
+			if !ok || e.Configuration.Type.DisplayName != "Build" {
+				continue
+			}
+			if e.Context.BuildID == 0 || e.Context.BuildID == oldBuild {
+				pending++
+				continue
+			}
+			builds = append(builds, e.Context.BuildID)
+		}
+		if pending == 0 {
+			return builds, nil
+		}
+	}
+}
+
+// queuePolicy re-queues a policy evaluation of a PR
+func queuePolicy(pat, orgURL, prID, evaluationID string) error {
+	_, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "repos", "pr", "policy", "queue",
+		"--id", prID,
+		"--evaluation-id", evaluationID,
+		"--org", orgURL,
+		"-o", "json",
+	)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(stderr))
+	}
+	return nil
+}
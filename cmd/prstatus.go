@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"defenders-cli/internal/utils"
+)
+
+const prStatusHelp = `pr status - Show policy, build and merge status of a Pull Request
+
+USAGE:
+  defenders pr status [<pr-url>|<pr-id>]
+
+ARGUMENTS:
+  <pr-url>|<pr-id>  PR to inspect (default: active PR of the current branch)
+
+FLAGS:
+  -t, --token  Personal Access Token (overrides config/env)
+
+EXIT CODES:
+  0  PR can be completed (or is already completed)
+  1  Error
+  2  Blocked by policies, required reviewers, draft state, or the PR is abandoned
+  3  Merge conflicts with the target branch
+
+EXAMPLES:
+  defenders pr status <url>
+  defenders pr status && defenders pr complete
+`
+
+// Exit codes of 'pr status'
+const (
+	prStatusMergeable = 0
+	prStatusBlocked   = 2
+	prStatusConflicts = 3
+)
+
+// policyIcon returns a status marker for a policy evaluation
+func policyIcon(status string) string {
+	switch status {
+	case "approved":
+		return "✓"
+	case "rejected", "broken":
+		return "✗"
+	case "queued", "running":
+		return "…"
+	default:
+		return "-"
+	}
+}
+
+func (p *PrhandlerCmd) showStatus() {
+	pat := utils.GetPAT(p.PAT)
+
+	pr, orgURL, err := resolvePR(pat, p.PRURL, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	prID := strconv.Itoa(pr.PullRequestID)
+	project := pr.Repository.Project.Name
+
+	fmt.Printf("PR #%s: %s\n", prID, pr.Title)
+	fmt.Printf("  %s -> %s | %s | merge: %s\n", branchName(pr.SourceRefName), branchName(pr.TargetRefName),
+		pr.Status, pr.MergeStatus)
+	fmt.Printf("  %s\n\n", prWebURL(pr))
+
+	switch pr.Status {
+	case "completed":
+		fmt.Println("✓ Completed")
+		os.Exit(prStatusMergeable)
+	case "abandoned":
+		fmt.Println("✗ Abandoned")
+		os.Exit(prStatusBlocked)
+	}
+
+	policies, err := listPolicies(pat, orgURL, prID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get policies: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Policies:")
+	if len(policies) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, e := range policies {
+		if !e.Configuration.IsEnabled {
+			continue
+		}
+		optional := ""
+		if !e.Configuration.IsBlocking {
+			optional = " (optional)"
+		}
+		status := e.Status
+		if e.Context.IsExpired {
+			status += ", expired"
+		}
+		fmt.Printf("  %s %-40s %s%s\n", policyIcon(e.Status), e.name(), status, optional)
+
+		// Build validation policies reference the latest validation build
+		if e.Context.BuildID != 0 {
+			build, err := getBuild(pat, orgURL, project, strconv.Itoa(e.Context.BuildID))
+			if err != nil {
+				fmt.Printf("      Build %d: %s\n", e.Context.BuildID, err)
+				continue
+			}
+			result := build.Status
+			if build.Result != "" {
+				result = build.Result
+			}
+			fmt.Printf("      Build %s: %s - %s\n", build.BuildNumber, result, buildWebURL(orgURL, project, build.ID))
+		}
+	}
+	fmt.Println()
+
+	reviewers, err := listReviewers(pat, orgURL, prID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get reviewers: %s\n", err)
+		os.Exit(1)
+	}
+	printReviewers(reviewers)
+	fmt.Println()
+
+	blockers := prBlockers(pr, policies, reviewers)
+	if len(blockers) == 0 {
+		fmt.Println("✓ Mergeable")
+		os.Exit(prStatusMergeable)
+	}
+
+	fmt.Println("✗ Not mergeable:")
+	for _, b := range blockers {
+		fmt.Printf("  - %s\n", b)
+	}
+	if pr.MergeStatus == "conflicts" {
+		os.Exit(prStatusConflicts)
+	}
+	os.Exit(prStatusBlocked)
+}
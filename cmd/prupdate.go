@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"defenders-cli/internal/utils"
+)
+
+const prUpdateHelp = `pr update - Update an existing Pull Request
+
+USAGE:
+  defenders pr update [<pr-url>|<pr-id>] [flags]
+
+ARGUMENTS:
+  <pr-url>|<pr-id>  PR to update (default: active PR of the current branch)
+
+FLAGS:
+  --title <text>              New PR title
+  -d, --description <text>    New PR description
+  -b, --target <branch>       New target branch
+  --draft                     Convert the PR to a draft
+  --publish                   Publish a draft PR
+  --add-reviewer <user>       Add a reviewer (repeatable)
+  --remove-reviewer <user>    Remove a reviewer (repeatable)
+  -i, --work-item <id>        Link a work item (repeatable)
+  --auto-complete             Set auto-complete
+  --cancel-auto-complete      Cancel auto-complete
+  --squash / --no-squash      Squash merge when auto-completing
+  --delete-source-branch      Delete the source branch after merge
+  --keep-source-branch        Keep the source branch after merge
+  --merge-message <text>      Merge commit message
+  -t, --token                 Personal Access Token (overrides config/env)
+
+EXAMPLES:
+  defenders pr update --title "Fix login redirect"
+  defenders pr update 123 --add-reviewer user@example.com -i 4567
+  defenders pr update <url> --publish --auto-complete --squash --delete-source-branch
+  defenders pr update <url> -b release/2024.10
+`
+
+func (p *PrhandlerCmd) updatePR() {
+	pat := utils.GetPAT(p.PAT)
+
+	pr, orgURL, err := resolvePR(pat, p.PRURL, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	identity := p.confirmIdentity(orgURL)
+
+	prID := strconv.Itoa(pr.PullRequestID)
+	fmt.Printf("Updating PR #%s: %s\n", prID, pr.Title)
+
+	// Labels of the changes made, for the audit log
+	var changes []string
+
+	// Fields handled by 'az repos pr update'
+	args := []string{"repos", "pr", "update", "--id", prID, "--org", orgURL, "-o", "json"}
+	fields := []struct {
+		flag  string
+		value string
+		label string
+	}{
+		{"--title", p.Title, "Title"},
+		{"--description", p.Description, "Description"},
+		{"--draft", p.Draft, "Draft"},
+		{"--auto-complete", p.AutoComplete, "Auto-complete"},
+		{"--squash", p.Squash, "Squash"},
+		{"--delete-source-branch", p.DeleteSourceBranch, "Delete source branch"},
+		{"--merge-commit-message", p.MergeMessage, "Merge message"},
+	}
+	hasFields := false
+	for _, f := range fields {
+		if f.value != "" {
+			args = append(args, f.flag, f.value)
+			hasFields = true
+		}
+	}
+	if hasFields {
+		_, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", args...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to update PR: %s\n", stderr)
+			os.Exit(1)
+		}
+		for _, f := range fields {
+			if f.value != "" {
+				fmt.Printf("  ✓ %s: %s\n", f.label, f.value)
+				changes = append(changes, strings.ToLower(f.label))
+			}
+		}
+	}
+
+	if p.Target != "" {
+		if err := retargetPR(pat, pr, p.Target); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to change target branch: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("  ✓ Target: %s -> %s\n", branchName(pr.TargetRefName), p.Target)
+		changes = append(changes, "target")
+	}
+
+	if len(p.AddReviewers) > 0 {
+		args := append([]string{"repos", "pr", "reviewer", "add", "--id", prID, "--org", orgURL, "--reviewers"}, p.AddReviewers...)
+		if _, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", args...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to add reviewers: %s\n", stderr)
+			os.Exit(1)
+		}
+		fmt.Printf("  ✓ Reviewers added: %v\n", p.AddReviewers)
+		changes = append(changes, "add reviewers")
+	}
+
+	if len(p.RemoveReviewers) > 0 {
+		args := append([]string{"repos", "pr", "reviewer", "remove", "--id", prID, "--org", orgURL, "--reviewers"}, p.RemoveReviewers...)
+		if _, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", args...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to remove reviewers: %s\n", stderr)
+			os.Exit(1)
+		}
+		fmt.Printf("  ✓ Reviewers removed: %v\n", p.RemoveReviewers)
+		changes = append(changes, "remove reviewers")
+	}
+
+	if len(p.WorkItems) > 0 {
+		args := append([]string{"repos", "pr", "work-item", "add", "--id", prID, "--org", orgURL, "--work-items"}, p.WorkItems...)
+		if _, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", args...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to link work items: %s\n", stderr)
+			os.Exit(1)
+		}
+		fmt.Printf("  ✓ Work items linked: %v\n", p.WorkItems)
+		changes = append(changes, "link work items")
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: Nothing to update")
+		fmt.Print(prUpdateHelp)
+		os.Exit(1)
+	}
+	auditAction(identity, p.PAT != "", prWebURL(pr), "update "+strings.Join(changes, ", "))
+
+	fmt.Println(prWebURL(pr))
+}
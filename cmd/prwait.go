@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+const prWaitHelp = `pr wait - Wait for a Pull Request to reach a state
+
+USAGE:
+  defenders pr wait [<pr-url>|<pr-id>] --until <condition> [flags]
+
+CONDITIONS:
+  approved       At least one approval, no rejections or waiting-for-author
+                 votes, and all required reviewers approved
+  policies-pass  All enabled blocking policies passed
+  completed      The PR was completed (fails if it is abandoned)
+
+FLAGS:
+  --until <condition>      Condition to wait for (required)
+  --timeout <duration>     Give up after this long, e.g. 30m or 2h (default: no timeout)
+  --interval <seconds>     Check interval in seconds (default: 30)
+  --then-complete          Complete the PR once the condition is met
+                           (accepts the 'pr complete' flags)
+  --then-run <pipeline>    Trigger a pipeline (definition URL, ID or name) once the
+                           condition is met
+  -t, --token              Personal Access Token (overrides config/env)
+
+Without a PR URL or ID, the active PR of the current branch is used.
+Exits with 1 when the timeout is reached, the PR is closed without meeting the
+condition (abandoned, or completed when waiting for approved or policies-pass),
+or the PR cannot be checked 5 times in a row.
+
+EXAMPLES:
+  defenders pr wait <url> --until approved --timeout 2h
+  defenders pr wait <url> --until policies-pass --then-complete --merge-strategy squash
+  defenders pr wait <url> --until completed --then-run "https://dev.azure.com/org/project/_build?definitionId=123"
+  defenders pr wait <url> --until completed --then-run "Nightly Deploy"
+`
+
+// prWaitConditions are the values accepted by --until
+var prWaitConditions = map[string]bool{
+	"approved":      true,
+	"policies-pass": true,
+	"completed":     true,
+}
+
+func (p *PrhandlerCmd) waitForPR() {
+	if !prWaitConditions[p.Until] {
+		if p.Until == "" {
+			fmt.Fprintln(os.Stderr, "Error: --until is required")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: Unknown condition '%s' (use approved, policies-pass or completed)\n", p.Until)
+		}
+		fmt.Print(prWaitHelp)
+		os.Exit(1)
+	}
+
+	if p.ThenComplete && p.Until == "completed" {
+		fmt.Fprintln(os.Stderr, "Error: --then-complete cannot be used with --until completed")
+		os.Exit(1)
+	}
+
+	var timeout time.Duration
+	if p.Timeout != "" {
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%s' (e.g. 30m or 2h)\n", p.Timeout)
+			os.Exit(1)
+		}
+		timeout = d
+	}
+
+	// Validate the follow-up pipeline before waiting, not after
+	var triggerOrgURL, triggerProject, definitionID string
+	var identity *utils.Identity
+	if p.ThenRun != "" {
+		var err error
+		triggerOrgURL, triggerProject, definitionID, err = resolveDefinition(utils.GetPAT(p.PAT), p.ThenRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Pipeline %s: %s\n", p.ThenRun, err)
+			os.Exit(1)
+		}
+		if identity, err = utils.ConfirmIdentity(p.PAT, triggerOrgURL); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 30
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	pr, orgURL, err := resolvePR(pat, p.PRURL, "active")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	prID := strconv.Itoa(pr.PullRequestID)
+
+	// Confirm who completes the PR now, not after the wait
+	if p.ThenComplete {
+		p.confirmIdentity(orgURL)
+	}
+
+	fmt.Printf("Waiting for PR #%s: %s\n", prID, pr.Title)
+	fmt.Printf("Condition: %s\n", p.Until)
+	if timeout > 0 {
+		fmt.Printf("Timeout: %s\n", timeout)
+	}
+	fmt.Printf("Check interval: %d seconds\n\n", interval)
+
+	deadline := time.Now().Add(timeout)
+	failures := 0
+	for {
+		met, status, err := prConditionMet(pat, orgURL, prID, p.Until)
+		if errors.Is(err, errPRClosed) {
+			fmt.Fprintf(os.Stderr, "\nError: PR #%s %s\n", prID, err)
+			os.Exit(1)
+		} else if err != nil {
+			failures++
+			if failures >= prCheckRetries {
+				fmt.Fprintf(os.Stderr, "\nError: Giving up after %d failed PR checks in a row: %s\n", failures, err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "Error checking PR status (%d/%d): %s\n", failures, prCheckRetries, err)
+		} else {
+			failures = 0
+			fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), status)
+			if met {
+				break
+			}
+		}
+
+		if timeout > 0 && time.Now().Add(time.Duration(interval)*time.Second).After(deadline) {
+			fmt.Fprintf(os.Stderr, "\nError: Timed out after %s waiting for PR #%s to be %s\n", timeout, prID, p.Until)
+			os.Exit(1)
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+
+	fmt.Printf("\n✓ PR #%s: %s\n", prID, p.Until)
+
+	if p.ThenComplete {
+		fmt.Println()
+		p.PRURL = prWebURL(pr)
+		p.completePR()
+	}
+
+	if p.ThenRun != "" {
+		fmt.Println("\nTriggering pipeline...")
+		build, err := triggerPipeline(pat, triggerOrgURL, triggerProject, definitionID, runOptions{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to trigger pipeline: %s\n", err)
+			os.Exit(1)
+		}
+		auditAction(identity, p.PAT != "", buildWebURL(triggerOrgURL, triggerProject, build.ID), "run pipeline "+definitionID)
+		fmt.Printf("Successfully triggered pipeline %d\n", build.ID)
+		fmt.Printf("URL: %s\n", buildWebURL(triggerOrgURL, triggerProject, build.ID))
+	}
+}
+
+// errPRClosed is returned by prConditionMet when the PR was closed without
+// meeting the condition, so waiting longer cannot succeed
+var errPRClosed = errors.New("was closed")
+
+// prConditionMet checks a 'pr wait' condition against the current PR state and
+// returns a one-line status. A closed PR only meets the completed condition.
+func prConditionMet(pat, orgURL, prID, condition string) (bool, string, error) {
+	pr, err := showPR(pat, orgURL, prID)
+	if err != nil {
+		return false, "", err
+	}
+
+	switch {
+	case pr.Status == "completed" && condition == "completed":
+		return true, "PR completed", nil
+	case pr.Status == "completed" || pr.Status == "abandoned":
+		return false, "", fmt.Errorf("%w: it is %s, not %s", errPRClosed, pr.Status, condition)
+	}
+
+	switch condition {
+	case "approved":
+		reviewers, err := listReviewers(pat, orgURL, prID)
+		if err != nil {
+			return false, "", err
+		}
+		approvals := 0
+		var waiting []string
+		for _, r := range reviewers {
+			switch {
+			case r.Vote >= 5:
+				approvals++
+			case r.Vote < 0:
+				waiting = append(waiting, fmt.Sprintf("%s: %s", r.DisplayName, voteLabel(r.Vote)))
+			case r.IsRequired:
+				waiting = append(waiting, fmt.Sprintf("required reviewer %s", r.DisplayName))
+			}
+		}
+		if approvals > 0 && len(waiting) == 0 {
+			return true, fmt.Sprintf("Approved by %d reviewer(s)", approvals), nil
+		}
+		if len(waiting) == 0 {
+			waiting = append(waiting, "no approvals yet")
+		}
+		return false, "Waiting on " + strings.Join(waiting, ", "), nil
+
+	case "policies-pass":
+		policies, err := listPolicies(pat, orgURL, prID)
+		if err != nil {
+			return false, "", err
+		}
+		var pending []string
+		for _, e := range policies {
+			if e.Configuration.IsEnabled && e.Configuration.IsBlocking && !e.passed() {
+				pending = append(pending, fmt.Sprintf("%s (%s)", e.name(), e.Status))
+			}
+		}
+		if len(pending) == 0 {
+			return true, "All blocking policies passed", nil
+		}
+		return false, "Waiting on " + strings.Join(pending, ", "), nil
+
+	default:
+		return false, fmt.Sprintf("PR is %s", pr.Status), nil
+	}
+}
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+// pullRequest holds the fields of an Azure DevOps PR that defenders commands use
+type pullRequest struct {
+	PullRequestID int       `json:"pullRequestId"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	Status        string    `json:"status"`
+	IsDraft       bool      `json:"isDraft"`
+	SourceRefName string    `json:"sourceRefName"`
+	TargetRefName string    `json:"targetRefName"`
+	MergeStatus   string    `json:"mergeStatus"`
+	URL           string    `json:"url"`
+	CreationDate  time.Time `json:"creationDate"`
+	CreatedBy     struct {
+		DisplayName string `json:"displayName"`
+		UniqueName  string `json:"uniqueName"`
+	} `json:"createdBy"`
+	Reviewers []reviewer `json:"reviewers"`
+
+	LastMergeSourceCommit struct {
+		CommitID string `json:"commitId"`
+	} `json:"lastMergeSourceCommit"`
+	LastMergeTargetCommit struct {
+		CommitID string `json:"commitId"`
+	} `json:"lastMergeTargetCommit"`
+	Repository struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Project struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"project"`
+	} `json:"repository"`
+}
+
+// reviewer is a PR reviewer with their vote
+type reviewer struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+	Vote        int    `json:"vote"`
+	IsRequired  bool   `json:"isRequired"`
+}
+
+// policyEvaluation is the result of a branch policy evaluated against a PR
+type policyEvaluation struct {
+	EvaluationID  string `json:"evaluationId"`
+	Status        string `json:"status"`
+	Configuration struct {
+		IsBlocking bool `json:"isBlocking"`
+		IsEnabled  bool `json:"isEnabled"`
+		Type       struct {
+			DisplayName string `json:"displayName"`
+		} `json:"type"`
+		Settings struct {
+			DisplayName string `json:"displayName"`
+		} `json:"settings"`
+	} `json:"configuration"`
+	Context struct {
+		BuildID   int  `json:"buildId"`
+		IsExpired bool `json:"isExpired"`
+	} `json:"context"`
+}
+
+// name returns the policy's own display name, falling back to its type
+func (e *policyEvaluation) name() string {
+	if e.Configuration.Settings.DisplayName != "" {
+		return e.Configuration.Settings.DisplayName
+	}
+	return e.Configuration.Type.DisplayName
+}
+
+// passed reports whether the policy no longer blocks completion
+func (e *policyEvaluation) passed() bool {
+	return e.Status == "approved" || e.Status == "notApplicable"
+}
+
+// voteLabel returns a readable name for an ADO vote value
+func voteLabel(vote int) string {
+	switch vote {
+	case 10:
+		return "approved"
+	case 5:
+		return "approved with suggestions"
+	case -5:
+		return "waiting for author"
+	case -10:
+		return "rejected"
+	default:
+		return "no vote"
+	}
+}
+
+// branchName strips the refs/heads/ prefix from a git ref
+func branchName(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
+
+// org returns the organization URL the PR belongs to, derived from its API URL
+func (pr *pullRequest) org() string {
+	if orgURL, _, _, err := utils.ResolveADOUrl(pr.URL); err == nil {
+		return orgURL
+	}
+	return utils.GetOrganization("")
+}
+
+// prWebURL returns the browser URL of a PR
+func prWebURL(pr *pullRequest) string {
+	org := pr.org()
+	project := pr.Repository.Project.Name
+	if project == "" {
+		project = utils.GetProject("")
+	}
+	return fmt.Sprintf("%s/%s/_git/%s/pullrequest/%d", org, project, pr.Repository.Name, pr.PullRequestID)
+}
+
+// listPRs runs 'az repos pr list' with the given extra arguments
+// Unless --org/--project are passed, the repository is detected from the git remote of the current directory
+func listPRs(pat string, args ...string) ([]pullRequest, error) {
+	azArgs := append([]string{"repos", "pr", "list", "-o", "json"}, args...)
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", azArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list PRs: %s", strings.TrimSpace(stderr))
+	}
+
+	var prs []pullRequest
+	if err := json.Unmarshal([]byte(stdout), &prs); err != nil {
+		return nil, fmt.Errorf("could not parse PR list: %w", err)
+	}
+	return prs, nil
+}
+
+// findLatestPR returns the most recently created PR from the source branch, or nil if none exists
+func findLatestPR(pat, source string) (*pullRequest, error) {
+	prs, err := listPRs(pat, "--source-branch", source, "--status", "all", "--top", "1")
+	if err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return &prs[0], nil
+}
+
+// findActivePR returns the active PR from source to target, or nil if none exists
+func findActivePR(pat, source, target string) (*pullRequest, error) {
+	prs, err := listPRs(pat, "--source-branch", source, "--target-branch", target, "--status", "active")
+	if err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return &prs[0], nil
+}
+
+// createPR opens a PR from source to target and returns it
+func createPR(pat, source, target, title, workItem string) (*pullRequest, error) {
+	args := []string{"repos", "pr", "create",
+		"-s", source,
+		"-t", target,
+		"--title", title,
+		"-o", "json",
+	}
+
+	if workItem != "" {
+		args = append(args, "--work-items", workItem)
+	}
+
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR:\n%s", stderr)
+	}
+
+	var pr pullRequest
+	if err := json.Unmarshal([]byte(stdout), &pr); err != nil {
+		return nil, fmt.Errorf("could not parse response: %w", err)
+	}
+	return &pr, nil
+}
+
+// retargetPR changes the target branch of an active PR
+// az repos pr update cannot change the target, so this goes through the REST API
+func retargetPR(pat string, pr *pullRequest, target string) error {
+	body := map[string]string{"targetRefName": "refs/heads/" + target}
+	return utils.ADORequest(pat, "PATCH", pr.URL+"?api-version=7.1", body, nil)
+}
+
+// showPR fetches a PR by ID from the given organization
+func showPR(pat, orgURL, prID string) (*pullRequest, error) {
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "repos", "pr", "show",
+		"--id", prID,
+		"--org", orgURL,
+		"-o", "json",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not get PR #%s: %s", prID, strings.TrimSpace(stderr))
+	}
+
+	var pr pullRequest
+	if err := json.Unmarshal([]byte(stdout), &pr); err != nil {
+		return nil, fmt.Errorf("could not parse PR: %w", err)
+	}
+	return &pr, nil
+}
+
+// getPR fetches a PR through the REST API using every part of its URL
+func getPR(pat, orgURL, project, repository, prID string) (*pullRequest, error) {
+	reqURL := fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests/%s?api-version=7.1",
+		orgURL, url.PathEscape(project), url.PathEscape(repository), url.PathEscape(prID))
+
+	var pr pullRequest
+	if err := utils.ADORequest(pat, "GET", reqURL, nil, &pr); err != nil {
+		return nil, fmt.Errorf("could not get PR #%s in %s/%s: %w", prID, project, repository, err)
+	}
+	return &pr, nil
+}
+
+// resolvePR finds a PR from a URL, a numeric ID, or - when ref is empty -
+// the PR with the given status (active, abandoned, ...) whose source is the current branch.
+// Returns the PR and the organization URL to use for az commands.
+// The organization comes from the PR itself; IDs are looked up in the configured organization.
+func resolvePR(pat, ref, status string) (*pullRequest, string, error) {
+	if ref == "" {
+		branch, err := utils.GetCurrentBranch()
+		if err != nil || branch == "" {
+			return nil, "", fmt.Errorf("no PR given and no branch checked out")
+		}
+		prs, err := listPRs(pat, "--source-branch", branch, "--status", status)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(prs) == 0 {
+			return nil, "", fmt.Errorf("no %s PR found for branch %s", status, branch)
+		}
+		return &prs[0], prs[0].org(), nil
+	}
+
+	if _, err := strconv.Atoi(ref); err == nil {
+		pr, err := showPR(pat, utils.GetOrganization(""), ref)
+		if err != nil {
+			return nil, "", err
+		}
+		return pr, pr.org(), nil
+	}
+
+	orgURL, project, repository, prID, err := parsePRUrl(ref)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not parse PR URL: %w", err)
+	}
+	pr, err := getPR(pat, orgURL, project, repository, prID)
+	if err != nil {
+		return nil, "", err
+	}
+	return pr, orgURL, nil
+}
+
+// listReviewers returns the reviewers of a PR with their current votes
+func listReviewers(pat, orgURL, prID string) ([]reviewer, error) {
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "repos", "pr", "reviewer", "list",
+		"--id", prID,
+		"--org", orgURL,
+		"-o", "json",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(stderr))
+	}
+
+	var reviewers []reviewer
+	if err := json.Unmarshal([]byte(stdout), &reviewers); err != nil {
+		return nil, fmt.Errorf("could not parse reviewers: %w", err)
+	}
+	return reviewers, nil
+}
+
+// printReviewers prints each reviewer with their vote
+func printReviewers(reviewers []reviewer) {
+	fmt.Println("Reviewers:")
+	if len(reviewers) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, r := range reviewers {
+		required := ""
+		if r.IsRequired {
+			required = " (required)"
+		}
+		fmt.Printf("  %-30s %s%s\n", r.DisplayName, voteLabel(r.Vote), required)
+	}
+}
+
+// postPRComment starts a new comment thread on a PR with the given thread status (active, closed, ...)
+func postPRComment(pat string, pr *pullRequest, text, status string) error {
+	body := map[string]interface{}{
+		"comments": []map[string]interface{}{
+			{"parentCommentId": 0, "content": text, "commentType": "text"},
+		},
+		"status": status,
+	}
+	return utils.ADORequest(pat, "POST", pr.URL+"/threads?api-version=7.1", body, nil)
+}
+
+// listPolicies returns the branch policy evaluations of a PR
+func listPolicies(pat, orgURL, prID string) ([]policyEvaluation, error) {
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "repos", "pr", "policy", "list",
+		"--id", prID,
+		"--org", orgURL,
+		"-o", "json",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(stderr))
+	}
+
+	var policies []policyEvaluation
+	if err := json.Unmarshal([]byte(stdout), &policies); err != nil {
+		return nil, fmt.Errorf("could not parse policies: %w", err)
+	}
+	return policies, nil
+}
+
+// policySummary condenses the blocking policy evaluations of a PR into one word
+func policySummary(policies []policyEvaluation) string {
+	summary := "passing"
+	blocking := 0
+	for _, p := range policies {
+		if !p.Configuration.IsEnabled || !p.Configuration.IsBlocking {
+			continue
+		}
+		blocking++
+		switch p.Status {
+		case "rejected", "broken":
+			return "failing"
+		case "queued", "running":
+			summary = "pending"
+		}
+	}
+	if blocking == 0 {
+		return "none"
+	}
+	return summary
+}
+
+// voteSummary counts reviewer votes, e.g. "2 approved, 1 waiting for author"
+func voteSummary(reviewers []reviewer) string {
+	counts := map[int]int{}
+	for _, r := range reviewers {
+		counts[r.Vote]++
+	}
+
+	var parts []string
+	for _, vote := range []int{10, 5, -5, -10, 0} {
+		if counts[vote] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[vote], voteLabel(vote)))
+		}
+	}
+	if len(parts) == 0 {
+		return "no reviewers"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// prBlockers returns a description of everything that prevents a PR from completing
+func prBlockers(pr *pullRequest, policies []policyEvaluation, reviewers []reviewer) []string {
+	var blockers []string
+
+	if pr.IsDraft {
+		blockers = append(blockers, "PR is a draft")
+	}
+	if pr.MergeStatus == "conflicts" {
+		blockers = append(blockers, "Merge conflicts with the target branch")
+	}
+	for _, p := range policies {
+		if p.Configuration.IsEnabled && p.Configuration.IsBlocking && !p.passed() {
+			blockers = append(blockers, fmt.Sprintf("Policy '%s' is %s", p.name(), p.Status))
+		}
+	}
+	for _, r := range reviewers {
+		if r.IsRequired && r.Vote < 5 {
+			blockers = append(blockers, fmt.Sprintf("Required reviewer %s: %s", r.DisplayName, voteLabel(r.Vote)))
+		}
+	}
+
+	return blockers
+}
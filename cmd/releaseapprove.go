@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"defenders-cli/internal/utils"
+)
+
+const releaseApproveHelp = `release approve - Approve or reject the pending approvals of a run
+
+USAGE:
+  defenders release approve <build-url> [--stage <name>] [--comment <text>] [--reject]
+
+Without --stage, the run must be waiting for approval on a single stage. The
+approvers of every pending approval are listed before asking for confirmation.
+
+ARGUMENTS:
+  <build-url>  URL of the pipeline run (buildId URL)
+
+FLAGS:
+  --stage <name>    Stage whose approval to answer (name or identifier)
+  --comment <text>  Comment recorded with the approval
+  --reject          Reject instead of approve
+  -t, --token       Personal Access Token (overrides config/env)
+  -f, --force       Do not ask for confirmation
+
+EXAMPLES:
+  defenders release approve https://dev.azure.com/org/proj/_build/results?buildId=123
+  defenders release approve <build-url> --stage Prod --comment "Change 4711 approved"
+  defenders release approve <build-url> --stage Prod --reject --comment "Freeze"
+`
+
+// pendingCheck is an approval or check a run is waiting on before a stage can start
+type pendingCheck struct {
+	Stage        string
+	StageID      string
+	Name         string
+	ApprovalID   string // empty for checks other than approvals
+	Approvers    []string
+	MinToApprove int
+	Instructions string
+}
+
+// String describes the check, e.g. "approval of stage Prod (approvers: A, B)"
+func (c pendingCheck) String() string {
+	if c.ApprovalID == "" {
+		return fmt.Sprintf("check '%s' of stage %s", c.Name, c.Stage)
+	}
+	s := "approval of stage " + c.Stage
+	if len(c.Approvers) > 0 {
+		s += " (approvers: " + strings.Join(c.Approvers, ", ") + ")"
+	}
+	return s
+}
+
+// approval is a pipeline approval with its approval steps
+type approval struct {
+	ID                   string `json:"id"`
+	Status               string `json:"status"`
+	Instructions         string `json:"instructions"`
+	MinRequiredApprovers int    `json:"minRequiredApprovers"`
+	Steps                []struct {
+		Status           string `json:"status"`
+		AssignedApprover struct {
+			DisplayName string `json:"displayName"`
+			UniqueName  string `json:"uniqueName"`
+		} `json:"assignedApprover"`
+	} `json:"steps"`
+}
+
+// pendingChecks returns the approvals and checks a run is waiting on. The
+// timeline has a Checkpoint.Approval record, whose ID is the approval ID, or
+// another Checkpoint.* record for every check in progress.
+func pendingChecks(pat, orgURL, project string, records []timelineRecord) ([]pendingCheck, error) {
+	byID := make(map[string]*timelineRecord)
+	for i := range records {
+		byID[records[i].ID] = &records[i]
+	}
+
+	var checks []pendingCheck
+	for i := range records {
+		r := &records[i]
+		if !strings.HasPrefix(r.Type, "Checkpoint.") || r.State != "inProgress" {
+			continue
+		}
+
+		check := pendingCheck{Name: r.Name}
+		for parent := byID[r.ParentID]; parent != nil; parent = byID[parent.ParentID] {
+			if parent.Type == "Stage" {
+				check.Stage, check.StageID = parent.Name, parent.Identifier
+				break
+			}
+		}
+
+		if r.Type == "Checkpoint.Approval" {
+			var a approval
+			reqURL := fmt.Sprintf("%s/%s/_apis/pipelines/approvals/%s?$expand=steps&api-version=7.1",
+				orgURL, url.PathEscape(project), r.ID)
+			if err := utils.ADORequest(pat, "GET", reqURL, nil, &a); err != nil {
+				return checks, fmt.Errorf("could not get approval of stage %s: %w", check.Stage, err)
+			}
+			if a.Status != "pending" {
+				continue
+			}
+			check.ApprovalID = a.ID
+			check.MinToApprove = a.MinRequiredApprovers
+			check.Instructions = a.Instructions
+			for _, step := range a.Steps {
+				if step.Status == "pending" {
+					check.Approvers = append(check.Approvers, step.AssignedApprover.DisplayName)
+				}
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// describeChecks returns a one-line summary of what a run is waiting on
+func describeChecks(checks []pendingCheck) string {
+	parts := make([]string, 0, len(checks))
+	for _, c := range checks {
+		parts = append(parts, c.String())
+	}
+	return "waiting for " + strings.Join(parts, "; ")
+}
+
+func (p *PiperunCmd) approveRun() {
+	if p.PipelineURL == "" {
+		fmt.Print(releaseApproveHelp)
+		os.Exit(1)
+	}
+
+	orgURL, project, buildID, err := parseBuildURL(p.PipelineURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing URL: %s\n", err)
+		os.Exit(1)
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	identity, err := utils.ConfirmIdentity(p.PAT, orgURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	build, err := getBuild(pat, orgURL, project, strconv.Itoa(buildID))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	records, err := getTimeline(pat, orgURL, project, buildID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get timeline: %s\n", err)
+		os.Exit(1)
+	}
+
+	checks, err := pendingChecks(pat, orgURL, project, records)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	var approvals []pendingCheck
+	for _, c := range checks {
+		if c.ApprovalID == "" {
+			fmt.Printf("  Waiting for %s\n", c)
+			continue
+		}
+		if p.Stage == "" || strings.EqualFold(c.Stage, p.Stage) || strings.EqualFold(c.StageID, p.Stage) {
+			approvals = append(approvals, c)
+		}
+	}
+
+	if len(approvals) == 0 {
+		if p.Stage != "" {
+			fmt.Fprintf(os.Stderr, "Error: %s #%s has no pending approval for stage '%s'\n",
+				build.Definition.Name, build.BuildNumber, p.Stage)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s #%s has no pending approvals\n", build.Definition.Name, build.BuildNumber)
+		}
+		os.Exit(1)
+	}
+
+	stages := make(map[string]bool)
+	for _, a := range approvals {
+		stages[a.Stage] = true
+	}
+	if len(stages) > 1 {
+		fmt.Fprintln(os.Stderr, "Error: Several stages are waiting for approval, choose one with --stage:")
+		for _, a := range approvals {
+			fmt.Fprintf(os.Stderr, "  %s\n", a.Stage)
+		}
+		os.Exit(1)
+	}
+
+	status, verb := "approved", "Approve"
+	if p.Reject {
+		status, verb = "rejected", "Reject"
+	}
+
+	fmt.Printf("%s #%s\n", build.Definition.Name, build.BuildNumber)
+	for _, a := range approvals {
+		fmt.Printf("  Stage %s\n", a.Stage)
+		if len(a.Approvers) > 0 {
+			fmt.Printf("    Approvers: %s", strings.Join(a.Approvers, ", "))
+			if a.MinToApprove > 0 {
+				fmt.Printf(" (%d required)", a.MinToApprove)
+			}
+			fmt.Println()
+		}
+		if a.Instructions != "" {
+			fmt.Printf("    Instructions: %s\n", firstLine(a.Instructions))
+		}
+	}
+
+	if !utils.AskUser("%s stage %s? [y/N]: ", verb, approvals[0].Stage) {
+		fmt.Println("Aborted.")
+		return
+	}
+
+	var body []map[string]string
+	for _, a := range approvals {
+		body = append(body, map[string]string{
+			"approvalId": a.ApprovalID,
+			"status":     status,
+			"comment":    p.Comment,
+		})
+	}
+	reqURL := fmt.Sprintf("%s/%s/_apis/pipelines/approvals?api-version=7.1", orgURL, url.PathEscape(project))
+	if err := utils.ADORequest(pat, "PATCH", reqURL, body, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not update approval: %s\n", err)
+		os.Exit(1)
+	}
+
+	auditAction(identity, p.PAT != "", buildWebURL(orgURL, project, buildID),
+		fmt.Sprintf("%s stage %s", strings.ToLower(verb), approvals[0].Stage))
+
+	fmt.Printf("✓ Stage %s %s\n", approvals[0].Stage, status)
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+const releaseArtifactsHelp = `release artifacts - List and download the artifacts of a pipeline run
+
+USAGE:
+  defenders release artifacts <build-url>
+  defenders release artifacts <build-url> --download <name> [--to <dir>] [--sha256 <hex>]
+
+ARGUMENTS:
+  <build-url>  URL of the pipeline run (buildId URL)
+
+FLAGS:
+  --download <name>  Artifact to download and extract
+  --to <dir>         Directory to extract into (default: current directory)
+  --sha256 <hex>     Expected SHA-256 of the downloaded archive
+  -t, --token        Personal Access Token (overrides config/env)
+
+An interrupted download is resumed when the same command is run again. Every
+extracted file is checked against the CRC-32 stored in the archive, and the
+SHA-256 of the archive is printed (and compared with --sha256 when given).
+
+EXAMPLES:
+  defenders release artifacts https://dev.azure.com/org/proj/_build/results?buildId=123
+  defenders release artifacts <build-url> --download drop --to ./signed
+`
+
+// buildArtifact is an artifact published by a pipeline run
+type buildArtifact struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Resource struct {
+		Type        string `json:"type"`
+		DownloadURL string `json:"downloadUrl"`
+		Properties  struct {
+			ArtifactSize string `json:"artifactsize"`
+		} `json:"properties"`
+	} `json:"resource"`
+}
+
+// listArtifacts returns the artifacts published by a build
+func listArtifacts(pat, orgURL, project string, buildID int) ([]buildArtifact, error) {
+	var data struct {
+		Value []buildArtifact `json:"value"`
+	}
+	reqURL := fmt.Sprintf("%s/%s/_apis/build/builds/%d/artifacts?api-version=7.1", orgURL, url.PathEscape(project), buildID)
+	if err := utils.ADORequest(pat, "GET", reqURL, nil, &data); err != nil {
+		return nil, err
+	}
+	return data.Value, nil
+}
+
+// formatSize returns a human readable size, e.g. "12.3 MB"
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTP"[exp])
+}
+
+func (p *PiperunCmd) artifacts() {
+	if p.PipelineURL == "" {
+		fmt.Print(releaseArtifactsHelp)
+		os.Exit(1)
+	}
+
+	orgURL, project, buildID, err := parseBuildURL(p.PipelineURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing URL: %s\n", err)
+		os.Exit(1)
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	artifacts, err := listArtifacts(pat, orgURL, project, buildID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not list artifacts: %s\n", err)
+		os.Exit(1)
+	}
+
+	if p.Download == "" {
+		if len(artifacts) == 0 {
+			fmt.Println("No artifacts published.")
+			return
+		}
+		for _, a := range artifacts {
+			size := ""
+			if n, err := strconv.ParseInt(a.Resource.Properties.ArtifactSize, 10, 64); err == nil {
+				size = formatSize(n)
+			}
+			fmt.Printf("  %-40s %-18s %s\n", a.Name, a.Resource.Type, size)
+		}
+		fmt.Printf("\n%d artifact(s). Download with --download <name>\n", len(artifacts))
+		return
+	}
+
+	var artifact *buildArtifact
+	for i := range artifacts {
+		if artifacts[i].Name == p.Download {
+			artifact = &artifacts[i]
+		}
+	}
+	if artifact == nil {
+		fmt.Fprintf(os.Stderr, "Error: Build %d has no artifact named '%s'\n", buildID, p.Download)
+		os.Exit(1)
+	}
+	if artifact.Resource.DownloadURL == "" {
+		fmt.Fprintf(os.Stderr, "Error: Artifact '%s' (%s) cannot be downloaded\n", artifact.Name, artifact.Resource.Type)
+		os.Exit(1)
+	}
+
+	dir := p.To
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not create %s: %s\n", dir, err)
+		os.Exit(1)
+	}
+
+	// Partial downloads are kept so that running the command again resumes them.
+	// The build ID keeps the same artifact of another run from being appended to.
+	archive := filepath.Join(dir, fmt.Sprintf("%s.%d.zip.part", artifact.Name, buildID))
+	if _, err := os.Stat(archive); err == nil {
+		fmt.Printf("Resuming download of %s...\n", artifact.Name)
+	} else {
+		fmt.Printf("Downloading %s...\n", artifact.Name)
+	}
+
+	lastPrint := time.Time{}
+	err = utils.ADODownload(pat, artifact.Resource.DownloadURL, archive, func(written, total int64) {
+		if time.Since(lastPrint) < 200*time.Millisecond && written != total {
+			return
+		}
+		lastPrint = time.Now()
+		if total > 0 {
+			fmt.Fprintf(os.Stderr, "\r  %s / %s (%d%%)   ", formatSize(written), formatSize(total), written*100/total)
+		} else {
+			fmt.Fprintf(os.Stderr, "\r  %s   ", formatSize(written))
+		}
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Download failed: %s\n", err)
+		fmt.Fprintln(os.Stderr, "Run the same command again to resume.")
+		os.Exit(1)
+	}
+
+	sum, err := fileSHA256(archive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("SHA-256: %s\n", sum)
+	if p.SHA256 != "" && !strings.EqualFold(sum, p.SHA256) {
+		os.Remove(archive)
+		fmt.Fprintf(os.Stderr, "Error: Checksum mismatch, expected %s. The download was removed.\n", p.SHA256)
+		os.Exit(1)
+	}
+
+	count, err := extractZip(archive, dir)
+	if err != nil {
+		// A corrupt archive cannot be resumed, the next attempt starts over
+		os.Remove(archive)
+		fmt.Fprintf(os.Stderr, "Error: Could not extract %s: %s\n", artifact.Name, err)
+		os.Exit(1)
+	}
+	os.Remove(archive)
+
+	fmt.Printf("✓ Extracted %d file(s) to %s\n", count, dir)
+}
+
+// fileSHA256 returns the hex SHA-256 of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("could not checksum %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extractZip extracts an archive into dir and returns the number of files.
+// Reading each file to the end verifies its CRC-32.
+func extractZip(archive, dir string) (int, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, f := range r.File {
+		target := filepath.Join(root, filepath.FromSlash(f.Name))
+		// Entries must not escape the target directory
+		if target != root && !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+			return count, fmt.Errorf("invalid path in archive: %s", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return count, err
+			}
+			continue
+		}
+
+		if err := extractZipFile(f, target); err != nil {
+			return count, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// extractZipFile writes one archive entry to target
+func extractZipFile(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"defenders-cli/internal/utils"
+)
+
+const releaseCancelHelp = `release cancel - Cancel a pipeline run
+
+USAGE:
+  defenders release cancel <build-url> [flags]
+
+ARGUMENTS:
+  <build-url>  URL of the pipeline run (buildId URL)
+
+FLAGS:
+  -t, --token  Personal Access Token (overrides config/env)
+  -f, --force  Cancel without asking for confirmation
+
+EXAMPLES:
+  defenders release cancel https://dev.azure.com/org/proj/_build/results?buildId=123
+`
+
+const releaseRetryHelp = `release retry - Rerun the failed jobs of a pipeline run
+
+USAGE:
+  defenders release retry <build-url> [flags]
+
+The run keeps its build ID; only failed and canceled jobs run again. With
+--stage, the failed jobs of that stage are rerun (multi-stage YAML pipelines).
+
+ARGUMENTS:
+  <build-url>  URL of the pipeline run (buildId URL)
+
+FLAGS:
+  --stage <name>            Retry only this stage (name or identifier)
+  -w, --wait                Wait for the run to finish (same exit codes as 'release run')
+  --timeout <duration>      Stop waiting after this long, e.g. 30m or 2h (with --wait)
+  -i, --interval <seconds>  Check interval when waiting (default: 30)
+  -t, --token               Personal Access Token (overrides config/env)
+  -f, --force               Do not ask for confirmation when using another user's PAT
+
+EXAMPLES:
+  defenders release retry https://dev.azure.com/org/proj/_build/results?buildId=123
+  defenders release retry <build-url> --stage Deploy --wait
+`
+
+const releaseRerunHelp = `release rerun - Start a new run like an earlier one
+
+USAGE:
+  defenders release rerun <build-url> [flags]
+
+Queues a new run of the same pipeline with the branch, commit, template
+parameters and queue-time variables of the original run.
+
+ARGUMENTS:
+  <build-url>  URL of the pipeline run to repeat (buildId URL)
+
+FLAGS:
+  -w, --wait                Wait for the new run to finish (same exit codes as 'release run')
+  --timeout <duration>      Stop waiting after this long, e.g. 30m or 2h (with --wait)
+  -i, --interval <seconds>  Check interval when waiting (default: 30)
+  -t, --token               Personal Access Token (overrides config/env)
+  -f, --force               Do not ask for confirmation when using another user's PAT
+
+EXAMPLES:
+  defenders release rerun https://dev.azure.com/org/proj/_build/results?buildId=123
+  defenders release rerun <build-url> --wait --timeout 2h
+`
+
+// retryableResults are the build results whose failed jobs can be rerun
+var retryableResults = map[string]bool{
+	"failed":             true,
+	"partiallySucceeded": true,
+	"canceled":           true,
+}
+
+// controlledBuild resolves the build URL of cancel, retry and rerun, confirms the
+// identity behind the PAT and fetches the build
+func (p *PiperunCmd) controlledBuild(help string) (pat, orgURL, project string, identity *utils.Identity, build *buildRun) {
+	if p.PipelineURL == "" {
+		fmt.Print(help)
+		os.Exit(1)
+	}
+
+	orgURL, project, buildID, err := parseBuildURL(p.PipelineURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing URL: %s\n", err)
+		os.Exit(1)
+	}
+
+	pat = utils.GetPAT(p.PAT)
+
+	identity, err = utils.ConfirmIdentity(p.PAT, orgURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	build, err = getBuild(pat, orgURL, project, strconv.Itoa(buildID))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	return pat, orgURL, project, identity, build
+}
+
+func (p *PiperunCmd) cancelRun() {
+	pat, orgURL, project, identity, build := p.controlledBuild(releaseCancelHelp)
+
+	if build.Status == "completed" {
+		fmt.Printf("%s #%s already completed with result: %s\n", build.Definition.Name, build.BuildNumber, build.Result)
+		return
+	}
+
+	if !utils.AskUser("Cancel %s #%s (%s)? [y/N]: ", build.Definition.Name, build.BuildNumber, build.Status) {
+		fmt.Println("Aborted.")
+		return
+	}
+
+	_, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", "pipelines", "build", "cancel",
+		"--build-id", strconv.Itoa(build.ID),
+		"--org", orgURL,
+		"--project", project,
+		"-o", "json",
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not cancel build: %s\n", strings.TrimSpace(stderr))
+		os.Exit(1)
+	}
+
+	auditAction(identity, p.PAT != "", buildWebURL(orgURL, project, build.ID), "cancel build")
+
+	fmt.Printf("✓ Canceling %s #%s\n", build.Definition.Name, build.BuildNumber)
+}
+
+func (p *PiperunCmd) retryRun() {
+	pat, orgURL, project, identity, build := p.controlledBuild(releaseRetryHelp)
+	timeout := p.waitTimeout()
+
+	if build.Status != "completed" {
+		fmt.Fprintf(os.Stderr, "Error: %s #%s is still %s, only completed runs can be retried\n",
+			build.Definition.Name, build.BuildNumber, build.Status)
+		os.Exit(1)
+	}
+	if !retryableResults[build.Result] {
+		fmt.Fprintf(os.Stderr, "Error: %s #%s %s, there is nothing to retry\n",
+			build.Definition.Name, build.BuildNumber, build.Result)
+		os.Exit(1)
+	}
+
+	base := fmt.Sprintf("%s/%s/_apis/build/builds/%d", orgURL, url.PathEscape(project), build.ID)
+	action := "retry build"
+
+	if p.Stage != "" {
+		records, err := getTimeline(pat, orgURL, project, build.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not get timeline: %s\n", err)
+			os.Exit(1)
+		}
+		var stage *timelineRecord
+		for i := range records {
+			r := &records[i]
+			if r.Type == "Stage" && (strings.EqualFold(r.Name, p.Stage) || strings.EqualFold(r.Identifier, p.Stage)) {
+				stage = r
+			}
+		}
+		if stage == nil {
+			fmt.Fprintf(os.Stderr, "Error: Run has no stage named '%s'\n", p.Stage)
+			os.Exit(1)
+		}
+
+		body := map[string]interface{}{"state": "retry", "forceRetryAllJobs": false}
+		reqURL := fmt.Sprintf("%s/stages/%s?api-version=7.1", base, url.PathEscape(stage.Identifier))
+		if err := utils.ADORequest(pat, "PATCH", reqURL, body, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not retry stage %s: %s\n", stage.Name, err)
+			os.Exit(1)
+		}
+		action = "retry stage " + stage.Identifier
+		fmt.Printf("✓ Retrying stage %s of %s #%s\n", stage.Name, build.Definition.Name, build.BuildNumber)
+	} else {
+		reqURL := base + "?retry=true&api-version=7.1"
+		if err := utils.ADORequest(pat, "PATCH", reqURL, map[string]interface{}{}, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not retry build: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Retrying the failed jobs of %s #%s\n", build.Definition.Name, build.BuildNumber)
+	}
+
+	auditAction(identity, p.PAT != "", buildWebURL(orgURL, project, build.ID), action)
+	fmt.Printf("URL: %s\n", buildWebURL(orgURL, project, build.ID))
+
+	if p.Wait {
+		p.waitAndExit(pat, orgURL, project, build.ID, timeout)
+	}
+}
+
+func (p *PiperunCmd) rerunRun() {
+	pat, orgURL, project, identity, build := p.controlledBuild(releaseRerunHelp)
+	timeout := p.waitTimeout()
+
+	opts, err := rerunOptions(build)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	definitionID := strconv.Itoa(build.Definition.ID)
+	fmt.Printf("Rerunning %s #%s\n", build.Definition.Name, build.BuildNumber)
+	fmt.Printf("Branch: %s\n", opts.Branch)
+	fmt.Printf("Commit: %s\n", opts.Commit)
+	for _, pair := range keyValuePairs(opts.Parameters) {
+		fmt.Printf("Parameter: %s\n", pair)
+	}
+	for _, pair := range keyValuePairs(opts.Variables) {
+		fmt.Printf("Variable: %s\n", pair)
+	}
+
+	newBuild, err := triggerPipeline(pat, orgURL, project, definitionID, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to trigger pipeline:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	auditAction(identity, p.PAT != "", buildWebURL(orgURL, project, newBuild.ID),
+		fmt.Sprintf("rerun build %d", build.ID))
+
+	fmt.Println("\nSuccessfully triggered pipeline!")
+	fmt.Printf("Build ID: %d\n", newBuild.ID)
+	fmt.Printf("URL: %s\n", buildWebURL(orgURL, project, newBuild.ID))
+
+	if p.Wait {
+		p.waitAndExit(pat, orgURL, project, newBuild.ID, timeout)
+	}
+}
+
+// rerunOptions returns the run options that repeat a build: its branch, commit,
+// template parameters and queue-time variables
+func rerunOptions(build *buildRun) (runOptions, error) {
+	opts := runOptions{
+		Branch:    build.SourceBranch,
+		Commit:    build.SourceVersion,
+		Variables: map[string]string{},
+	}
+
+	params, err := stringifyParams(build.TemplateParameters)
+	if err != nil {
+		return opts, err
+	}
+	opts.Parameters = params
+
+	if build.Parameters != "" {
+		var variables map[string]interface{}
+		if err := json.Unmarshal([]byte(build.Parameters), &variables); err != nil {
+			return opts, fmt.Errorf("could not parse the variables of build %d: %w", build.ID, err)
+		}
+		if opts.Variables, err = stringifyParams(variables); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
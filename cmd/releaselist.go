@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+const releaseListHelp = `release list - List pipeline definitions
+
+USAGE:
+  defenders release list [--name <pattern>] [--folder <path>]
+
+Lists the pipelines of the configured organization and project (see
+'defenders conf', ADO_ORG and ADO_PROJECT).
+
+FLAGS:
+  --name <pattern>  Only pipelines whose name contains the pattern (* is a wildcard)
+  --folder <path>   Only pipelines in this folder, e.g. \Release or Release/Prod
+  -t, --token       Personal Access Token (overrides config/env)
+
+EXAMPLES:
+  defenders release list --name deploy
+  defenders release list --folder Release
+`
+
+const releaseRunsHelp = `release runs - List recent runs of a pipeline
+
+USAGE:
+  defenders release runs <pipeline> [flags]
+
+ARGUMENTS:
+  <pipeline>  Pipeline definition URL, ID or name (see 'release list')
+
+FLAGS:
+  -b, --branch <name>  Only runs of this branch
+  --result <result>    Only runs with this result: succeeded, partiallySucceeded,
+                       failed or canceled
+  --top <n>            Number of runs to show (default: 10)
+  -t, --token          Personal Access Token (overrides config/env)
+
+EXAMPLES:
+  defenders release runs 456
+  defenders release runs "Deploy Service" --branch main --result failed
+  defenders release runs https://dev.azure.com/org/proj/_build?definitionId=456 --top 25
+`
+
+// maxAmbiguousMatches is the number of candidates listed when a pipeline name is ambiguous
+const maxAmbiguousMatches = 10
+
+// pipelineDefinition is a pipeline as listed by 'az pipelines list'
+type pipelineDefinition struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// fullName returns the folder and name of the pipeline, e.g. "Release\Deploy"
+func (d pipelineDefinition) fullName() string {
+	folder := strings.Trim(d.Path, `\`)
+	if folder == "" {
+		return d.Name
+	}
+	return folder + `\` + d.Name
+}
+
+// definitionWebURL returns the browser URL of a pipeline definition
+func definitionWebURL(orgURL, project string, definitionID int) string {
+	return fmt.Sprintf("%s/%s/_build?definitionId=%d", orgURL, project, definitionID)
+}
+
+// isURL reports whether a pipeline reference is a URL rather than a name or ID
+func isURL(ref string) bool {
+	return strings.Contains(ref, "://")
+}
+
+// listDefinitions returns the pipelines of a project. name may contain * wildcards
+// and is matched anywhere in the pipeline name.
+func listDefinitions(pat, orgURL, project, name, folder string) ([]pipelineDefinition, error) {
+	args := []string{"pipelines", "list",
+		"--org", orgURL,
+		"--project", project,
+		"--query-order", "NameAsc",
+		"-o", "json",
+	}
+	if name != "" {
+		args = append(args, "--name", "*"+strings.Trim(name, "*")+"*")
+	}
+	if folder != "" {
+		folder = strings.ReplaceAll(folder, "/", `\`)
+		if !strings.HasPrefix(folder, `\`) {
+			folder = `\` + folder
+		}
+		args = append(args, "--folder-path", folder)
+	}
+
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list pipelines: %s", strings.TrimSpace(stderr))
+	}
+
+	var definitions []pipelineDefinition
+	if err := json.Unmarshal([]byte(stdout), &definitions); err != nil {
+		return nil, fmt.Errorf("could not parse pipelines: %w", err)
+	}
+	return definitions, nil
+}
+
+// resolveDefinition resolves a pipeline reference to org, project and definition
+// ID. A reference is a definition URL, or an ID or name in the configured org and
+// project. Names match case-insensitively, either exactly (with or without folder)
+// or as the only pipeline containing them.
+func resolveDefinition(pat, ref string) (orgURL, project, definitionID string, err error) {
+	if isURL(ref) {
+		return parseDefinitionURL(ref)
+	}
+
+	orgURL = strings.TrimRight(utils.GetOrganization(""), "/")
+	project = utils.GetProject("")
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", "", "", fmt.Errorf("no pipeline given")
+	}
+	if _, err := strconv.Atoi(ref); err == nil {
+		return orgURL, project, ref, nil
+	}
+
+	wanted := strings.Trim(strings.ReplaceAll(ref, "/", `\`), `\`)
+	name := wanted
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	candidates, err := listDefinitions(pat, orgURL, project, name, "")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var exact []pipelineDefinition
+	for _, d := range candidates {
+		if strings.EqualFold(d.fullName(), wanted) || strings.EqualFold(d.Name, wanted) {
+			exact = append(exact, d)
+		}
+	}
+	if len(exact) == 1 {
+		return orgURL, project, strconv.Itoa(exact[0].ID), nil
+	}
+	if len(exact) > 1 {
+		candidates = exact
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", "", "", fmt.Errorf("no pipeline named '%s' in %s/%s", ref, orgURL, project)
+	case 1:
+		return orgURL, project, strconv.Itoa(candidates[0].ID), nil
+	}
+
+	var names []string
+	for i, d := range candidates {
+		if i == maxAmbiguousMatches {
+			names = append(names, fmt.Sprintf("  ... and %d more", len(candidates)-maxAmbiguousMatches))
+			break
+		}
+		names = append(names, fmt.Sprintf("  %6d  %s", d.ID, d.fullName()))
+	}
+	return "", "", "", fmt.Errorf("'%s' matches %d pipelines, use the ID or folder\\name:\n%s",
+		ref, len(candidates), strings.Join(names, "\n"))
+}
+
+func (p *PiperunCmd) listPipelines() {
+	pat := utils.GetPAT(p.PAT)
+	orgURL := strings.TrimRight(utils.GetOrganization(""), "/")
+	project := utils.GetProject("")
+
+	definitions, err := listDefinitions(pat, orgURL, project, p.NamePattern, p.Folder)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if len(definitions) == 0 {
+		fmt.Println("No pipelines found.")
+		return
+	}
+
+	for _, d := range definitions {
+		fmt.Printf("  %6d  %s\n", d.ID, d.fullName())
+	}
+	fmt.Printf("\n%d pipeline(s) in %s/%s\n", len(definitions), orgURL, project)
+}
+
+func (p *PiperunCmd) listRuns() {
+	if p.PipelineURL == "" {
+		fmt.Print(releaseRunsHelp)
+		os.Exit(1)
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	orgURL, project, definitionID, err := resolveDefinition(pat, p.PipelineURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	top := p.Top
+	if top <= 0 {
+		top = 10
+	}
+
+	args := []string{"pipelines", "runs", "list",
+		"--pipeline-ids", definitionID,
+		"--org", orgURL,
+		"--project", project,
+		"--query-order", "QueueTimeDesc",
+		"--top", strconv.Itoa(top),
+		"-o", "json",
+	}
+	if p.Branch != "" {
+		args = append(args, "--branch", p.Branch)
+	}
+	if p.Result != "" {
+		args = append(args, "--result", p.Result)
+	}
+
+	stdout, stderr, err := utils.RunCommandWithOptionalPAT(pat, "az", args...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not list runs: %s\n", strings.TrimSpace(stderr))
+		os.Exit(1)
+	}
+
+	var runs []struct {
+		buildRun
+		QueueTime    time.Time `json:"queueTime"`
+		RequestedFor struct {
+			DisplayName string `json:"displayName"`
+		} `json:"requestedFor"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &runs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not parse runs: %s\n", err)
+		os.Exit(1)
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("No runs found.")
+		return
+	}
+
+	fmt.Printf("%s\n\n", runs[0].Definition.Name)
+	for _, r := range runs {
+		status := r.Result
+		if status == "" {
+			status = r.Status
+		}
+		fmt.Printf("  %-8d %-22s %-18s %-30s %4s  %s\n", r.ID, r.BuildNumber, status,
+			branchName(r.SourceBranch), formatAge(r.QueueTime), r.RequestedFor.DisplayName)
+	}
+	fmt.Printf("\nLatest: %s\n", buildWebURL(orgURL, project, runs[0].ID))
+}
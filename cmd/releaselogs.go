@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+)
+
+const releaseLogsHelp = `release logs - Show the timeline and logs of a pipeline run
+
+USAGE:
+  defenders release logs <build-url> [flags]
+
+Prints the stages, jobs and tasks of the run with their result and duration,
+followed by the logs of the failed tasks.
+
+ARGUMENTS:
+  <build-url>  URL of the pipeline run (buildId URL)
+
+FLAGS:
+  --task <name>    Print the log of this task instead of the failed ones
+                   (matches every task with that name, case-insensitive)
+  --follow         Keep printing new log lines until the run completes
+  -i, --interval   Check interval in seconds with --follow (default: 30)
+  -t, --token      Personal Access Token (overrides config/env)
+
+With --follow, new lines are read from each task's log from the last line shown.
+Azure DevOps usually publishes a task's log only once the task ends, so a
+running task is announced when it starts and its output follows when available.
+
+EXAMPLES:
+  defenders release logs https://dev.azure.com/org/proj/_build/results?buildId=123
+  defenders release logs <build-url> --task "Run tests"
+  defenders release logs <build-url> --follow
+`
+
+// recordIcon returns a status marker for a timeline record
+func recordIcon(r *timelineRecord) string {
+	switch r.Result {
+	case "succeeded":
+		return "✓"
+	case "failed":
+		return "✗"
+	case "succeededWithIssues":
+		return "!"
+	case "canceled", "abandoned":
+		return "-"
+	case "skipped":
+		return " "
+	}
+	if r.State == "inProgress" {
+		return "…"
+	}
+	return " "
+}
+
+func (p *PiperunCmd) showLogs() {
+	if p.PipelineURL == "" {
+		fmt.Print(releaseLogsHelp)
+		os.Exit(1)
+	}
+
+	orgURL, project, buildID, err := parseBuildURL(p.PipelineURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing URL: %s\n", err)
+		os.Exit(1)
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	build, err := getBuild(pat, orgURL, project, strconv.Itoa(buildID))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	records, err := getTimeline(pat, orgURL, project, buildID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get timeline: %s\n", err)
+		os.Exit(1)
+	}
+
+	status := build.Status
+	if build.Result != "" {
+		status = build.Result
+	}
+	fmt.Printf("%s #%s: %s\n\n", build.Definition.Name, build.BuildNumber, status)
+	printTimeline(records)
+
+	if p.Follow {
+		p.followLogs(pat, orgURL, project, buildID)
+		return
+	}
+
+	tasks := p.selectTasks(records)
+	if len(tasks) == 0 {
+		if p.Task != "" {
+			fmt.Fprintf(os.Stderr, "\nError: No task named '%s'\n", p.Task)
+			os.Exit(1)
+		}
+		fmt.Println("\nNo failed tasks.")
+		return
+	}
+
+	for _, task := range tasks {
+		printTaskHeader(records, task)
+		if task.Log == nil {
+			fmt.Println("(no log)")
+			continue
+		}
+		text, err := getBuildLog(pat, orgURL, project, buildID, task.Log.ID, 1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not get log: %s\n", err)
+			continue
+		}
+		fmt.Print(text)
+	}
+}
+
+// selectTasks returns the tasks whose logs are printed: the one named by --task,
+// or the failed ones
+func (p *PiperunCmd) selectTasks(records []timelineRecord) []*timelineRecord {
+	var tasks []*timelineRecord
+	for i := range records {
+		r := &records[i]
+		if r.Type != "Task" {
+			continue
+		}
+		if p.Task != "" && strings.EqualFold(r.Name, p.Task) {
+			tasks = append(tasks, r)
+		}
+		if p.Task == "" && r.Result == "failed" {
+			tasks = append(tasks, r)
+		}
+	}
+	sortByStart(tasks)
+	return tasks
+}
+
+// followLogs prints new log lines of the run's tasks until the run completes
+func (p *PiperunCmd) followLogs(pat, orgURL, project string, buildID int) {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 30
+	}
+
+	// Lines already printed per log, and running tasks already announced
+	printed := make(map[int]int)
+	announced := make(map[string]bool)
+	var current *timelineRecord
+
+	for {
+		build, err := getBuild(pat, orgURL, project, strconv.Itoa(buildID))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking build status: %s\n", err)
+			time.Sleep(time.Duration(interval) * time.Second)
+			continue
+		}
+
+		records, err := getTimeline(pat, orgURL, project, buildID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not get timeline: %s\n", err)
+			time.Sleep(time.Duration(interval) * time.Second)
+			continue
+		}
+
+		var tasks []*timelineRecord
+		for i := range records {
+			r := &records[i]
+			if r.Type == "Task" && (r.Log != nil || r.State == "inProgress") &&
+				(p.Task == "" || strings.EqualFold(r.Name, p.Task)) {
+				tasks = append(tasks, r)
+			}
+		}
+		sortByStart(tasks)
+
+		for _, task := range tasks {
+			// A running task often has no log yet, show that it started
+			if task.Log == nil {
+				if !announced[task.ID] {
+					printTaskHeader(records, task)
+					fmt.Println("(running, output appears once its log is published)")
+					announced[task.ID] = true
+					current = task
+				}
+				continue
+			}
+			text, err := getBuildLog(pat, orgURL, project, buildID, task.Log.ID, printed[task.Log.ID]+1)
+			if err != nil || text == "" {
+				continue
+			}
+			if current == nil || current.ID != task.ID {
+				printTaskHeader(records, task)
+				current = task
+			}
+			fmt.Print(text)
+			if !strings.HasSuffix(text, "\n") {
+				fmt.Println()
+			}
+			printed[task.Log.ID] += strings.Count(strings.TrimSuffix(text, "\n"), "\n") + 1
+		}
+
+		if build.Status == "completed" {
+			fmt.Printf("\nRun completed with result: %s\n", build.Result)
+			return
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
+// getBuildLog returns the lines of a build log starting at startLine (1-based)
+func getBuildLog(pat, orgURL, project string, buildID, logID, startLine int) (string, error) {
+	reqURL := fmt.Sprintf("%s/%s/_apis/build/builds/%d/logs/%d?startLine=%d&api-version=7.1",
+		orgURL, url.PathEscape(project), buildID, logID, startLine)
+	return utils.ADOGetText(pat, reqURL)
+}
+
+// printTimeline prints stages, jobs and tasks as a tree with result and duration.
+// Phases are not shown, their jobs are printed under the stage.
+func printTimeline(records []timelineRecord) {
+	children := make(map[string][]*timelineRecord)
+	for i := range records {
+		r := &records[i]
+		children[r.ParentID] = append(children[r.ParentID], r)
+	}
+	for _, list := range children {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Order < list[j].Order })
+	}
+
+	var walk func(parentID string, depth int)
+	walk = func(parentID string, depth int) {
+		for _, r := range children[parentID] {
+			switch r.Type {
+			case "Phase":
+				walk(r.ID, depth)
+				continue
+			case "Stage", "Job", "Task":
+			default:
+				continue
+			}
+
+			status := r.Result
+			if status == "" {
+				status = r.State
+			}
+			duration := ""
+			if d := r.duration(); d > 0 {
+				duration = d.String()
+			}
+			name := r.Name
+			if r.Type != "Task" {
+				name = r.Type + " " + r.Name
+			}
+			line := fmt.Sprintf("%s%s %-*s %-20s %s", strings.Repeat("  ", depth), recordIcon(r),
+				48-2*depth, name, status, duration)
+			fmt.Println(strings.TrimRight(line, " "))
+			for _, issue := range r.Issues {
+				if issue.Type == "error" {
+					fmt.Printf("%s    %s\n", strings.Repeat("  ", depth), issue.Message)
+				}
+			}
+			walk(r.ID, depth+1)
+		}
+	}
+	walk("", 0)
+}
+
+// printTaskHeader prints the job and name of a task before its log
+func printTaskHeader(records []timelineRecord, task *timelineRecord) {
+	job := ""
+	for _, r := range records {
+		if r.ID == task.ParentID {
+			job = r.Name + " / "
+		}
+	}
+	fmt.Printf("\n===== %s%s =====\n", job, task.Name)
+}
+
+// sortByStart orders records by start time, records that did not start last
+func sortByStart(records []*timelineRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i].StartTime, records[j].StartTime
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+}
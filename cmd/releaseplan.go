@@ -0,0 +1,501 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"defenders-cli/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+const releasePlanHelp = `release plan run - Run a chain or graph of pipelines from a release plan
+
+USAGE:
+  defenders release plan run <plan.yaml> [flags]
+
+Pipelines start as soon as the pipelines they depend on have finished, so
+independent branches of the plan run in parallel. Progress is saved next to the
+plan (<plan.yaml>.state.json): running the same plan again after the CLI was
+stopped resumes it, following builds that were already started. The progress is
+removed once every pipeline has finished, so the next run starts a new release.
+
+PLAN FORMAT:
+  name: Weekly release
+  pipelines:
+    - name: build
+      url: https://dev.azure.com/org/proj/_build?definitionId=1
+      branch: main                   # optional
+      parameters: {environment: prod} # optional template parameters
+      variables: {dryRun: "false"}   # optional queue-time variables
+      stages_to_skip: [Canary]       # optional
+    - name: deploy-eu
+      url: https://dev.azure.com/org/proj/_build?definitionId=2
+      depends_on: [build]
+      on: succeeded                  # succeeded (default) or always
+    - name: notify
+      pipeline: Release\Notify      # name or ID instead of url (configured org/project)
+      depends_on: [deploy-eu]
+      on: always
+
+  on: succeeded  run only when every dependency succeeded, otherwise skip
+  on: always     run once every dependency finished, whatever the result
+
+FLAGS:
+  --restart             Ignore saved progress and run the whole plan again
+  -i, --interval        Check interval in seconds (default: 30)
+  --max-retries <n>     Failed status checks in a row before giving up (default: 5)
+  -t, --token           Personal Access Token (overrides config/env)
+  -f, --force           Do not ask for confirmation when using another user's PAT
+
+EXIT CODES:
+  0  All pipelines succeeded
+  1  Error
+  2  A pipeline did not succeed or was skipped
+  6  A run's status could not be checked --max-retries times in a row (the
+     progress is kept, running the plan again resumes it)
+`
+
+// releasePlan is a release plan file
+type releasePlan struct {
+	Name      string         `yaml:"name"`
+	Pipelines []planPipeline `yaml:"pipelines"`
+}
+
+// planPipeline is one pipeline of a release plan
+type planPipeline struct {
+	Name         string                 `yaml:"name"`
+	URL          string                 `yaml:"url"`
+	Pipeline     string                 `yaml:"pipeline"` // name or ID, instead of url
+	Branch       string                 `yaml:"branch"`
+	Parameters   map[string]interface{} `yaml:"parameters"`
+	Variables    map[string]interface{} `yaml:"variables"`
+	StagesToSkip []string               `yaml:"stages_to_skip"`
+	DependsOn    []string               `yaml:"depends_on"`
+	On           string                 `yaml:"on"`
+}
+
+// planStep is the saved progress of one pipeline of a plan
+type planStep struct {
+	Status     string    `json:"status"` // pending, running, completed or skipped
+	Result     string    `json:"result,omitempty"`
+	BuildID    int       `json:"buildId,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	StartedAt  time.Time `json:"startedAt,omitempty"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+// finished reports whether the step will not change anymore
+func (s *planStep) finished() bool {
+	return s.Status == "completed" || s.Status == "skipped"
+}
+
+// loadReleasePlan reads and validates a release plan
+func loadReleasePlan(path string) (*releasePlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read plan: %w", err)
+	}
+
+	var plan releasePlan
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("could not parse plan %s: %w", path, err)
+	}
+
+	if len(plan.Pipelines) == 0 {
+		return nil, fmt.Errorf("plan %s has no pipelines", path)
+	}
+
+	names := make(map[string]bool)
+	for i := range plan.Pipelines {
+		pl := &plan.Pipelines[i]
+		if pl.Name == "" {
+			return nil, fmt.Errorf("pipeline %d has no name", i+1)
+		}
+		if names[pl.Name] {
+			return nil, fmt.Errorf("pipeline name '%s' is used twice", pl.Name)
+		}
+		names[pl.Name] = true
+
+		switch {
+		case pl.URL != "" && pl.Pipeline != "":
+			return nil, fmt.Errorf("pipeline '%s': use either url or pipeline, not both", pl.Name)
+		case pl.Pipeline != "":
+			// Resolved against the configured org and project when the plan runs
+		default:
+			if _, _, _, err := parseDefinitionURL(pl.URL); err != nil {
+				return nil, fmt.Errorf("pipeline '%s': %w", pl.Name, err)
+			}
+		}
+		switch pl.On {
+		case "":
+			pl.On = "succeeded"
+		case "succeeded", "always":
+		default:
+			return nil, fmt.Errorf("pipeline '%s': unknown condition 'on: %s' (use succeeded or always)", pl.Name, pl.On)
+		}
+	}
+
+	for _, pl := range plan.Pipelines {
+		for _, dep := range pl.DependsOn {
+			if !names[dep] {
+				return nil, fmt.Errorf("pipeline '%s' depends on unknown pipeline '%s'", pl.Name, dep)
+			}
+		}
+	}
+
+	if cycle := planCycle(plan.Pipelines); cycle != "" {
+		return nil, fmt.Errorf("dependency cycle: %s", cycle)
+	}
+
+	return &plan, nil
+}
+
+// planCycle returns a description of a dependency cycle, or an empty string
+func planCycle(pipelines []planPipeline) string {
+	deps := make(map[string][]string)
+	for _, pl := range pipelines {
+		deps[pl.Name] = pl.DependsOn
+	}
+
+	// 0 = unvisited, 1 = on the current path, 2 = done
+	state := make(map[string]int)
+	var path []string
+	var visit func(name string) string
+	visit = func(name string) string {
+		switch state[name] {
+		case 1:
+			return strings.Join(append(path, name), " -> ")
+		case 2:
+			return ""
+		}
+		state[name] = 1
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if cycle := visit(dep); cycle != "" {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = 2
+		return ""
+	}
+
+	for _, pl := range pipelines {
+		if cycle := visit(pl.Name); cycle != "" {
+			return cycle
+		}
+	}
+	return ""
+}
+
+// loadPlanState reads saved progress, or returns empty progress when there is none
+func loadPlanState(path string) (map[string]*planStep, error) {
+	steps := make(map[string]*planStep)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return steps, nil
+		}
+		return nil, fmt.Errorf("could not read plan state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("could not parse plan state %s: %w", path, err)
+	}
+	return steps, nil
+}
+
+// savePlanState writes the progress of a plan, replacing the file atomically so
+// that a killed CLI never leaves a truncated state behind
+func savePlanState(path string, steps map[string]*planStep) error {
+	data, err := json.MarshalIndent(steps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialize plan state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("could not write plan state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not write plan state: %w", err)
+	}
+	return nil
+}
+
+func (p *PiperunCmd) runPlan() {
+	if p.PlanAction != "run" || p.PlanFile == "" {
+		fmt.Print(releasePlanHelp)
+		os.Exit(1)
+	}
+
+	plan, err := loadReleasePlan(p.PlanFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	statePath := p.PlanFile + ".state.json"
+	steps := make(map[string]*planStep)
+	if !p.Restart {
+		if steps, err = loadPlanState(statePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	resumed := len(steps) > 0
+	for _, pl := range plan.Pipelines {
+		if steps[pl.Name] == nil {
+			steps[pl.Name] = &planStep{Status: "pending"}
+		}
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	for i := range plan.Pipelines {
+		pl := &plan.Pipelines[i]
+		if pl.Pipeline == "" {
+			continue
+		}
+		orgURL, project, definitionID, err := resolveDefinition(pat, pl.Pipeline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Pipeline '%s': %s\n", pl.Name, err)
+			os.Exit(1)
+		}
+		id, _ := strconv.Atoi(definitionID)
+		pl.URL = definitionWebURL(orgURL, project, id)
+	}
+
+	// Pipelines can live in different organizations, each one is confirmed once
+	identities := make(map[string]*utils.Identity)
+	var orgs []string
+	for _, pl := range plan.Pipelines {
+		orgURL, _, _, _ := parseDefinitionURL(pl.URL)
+		if _, ok := identities[orgURL]; !ok {
+			identities[orgURL] = nil
+			orgs = append(orgs, orgURL)
+		}
+	}
+	for _, orgURL := range orgs {
+		if len(orgs) > 1 {
+			fmt.Printf("Organization: %s\n", orgURL)
+		}
+		identity, err := utils.ConfirmIdentity(p.PAT, orgURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		identities[orgURL] = identity
+	}
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 30
+	}
+
+	title := plan.Name
+	if title == "" {
+		title = p.PlanFile
+	}
+	fmt.Printf("Release plan: %s (%d pipelines)\n", title, len(plan.Pipelines))
+	if resumed {
+		fmt.Printf("Resuming from %s (use --restart to start over)\n", statePath)
+	}
+	fmt.Printf("Check interval: %d seconds\n\n", interval)
+	printPlanTable(plan, steps)
+
+	// Failed status checks in a row, across the running builds
+	failures := 0
+	maxRetries := p.maxRetries()
+
+	for {
+		changed := false
+		checkFailed := false
+
+		// Follow running builds
+		for _, pl := range plan.Pipelines {
+			step := steps[pl.Name]
+			if step.Status != "running" {
+				continue
+			}
+			orgURL, project, _, _ := parseDefinitionURL(pl.URL)
+			build, err := getBuild(pat, orgURL, project, strconv.Itoa(step.BuildID))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking '%s': %s\n", pl.Name, err)
+				checkFailed = true
+				continue
+			}
+			if build.Status == "completed" {
+				step.Status = "completed"
+				step.Result = build.Result
+				step.FinishedAt = time.Now()
+				changed = true
+			}
+		}
+
+		// Start or skip pipelines whose dependencies have finished
+		for _, pl := range plan.Pipelines {
+			step := steps[pl.Name]
+			if step.Status != "pending" {
+				continue
+			}
+
+			ready, allSucceeded := true, true
+			for _, dep := range pl.DependsOn {
+				d := steps[dep]
+				if !d.finished() {
+					ready = false
+				}
+				if d.Result != "succeeded" {
+					allSucceeded = false
+				}
+			}
+			if !ready {
+				continue
+			}
+			changed = true
+
+			if pl.On == "succeeded" && !allSucceeded {
+				step.Status = "skipped"
+				step.FinishedAt = time.Now()
+				continue
+			}
+
+			orgURL, _, _, _ := parseDefinitionURL(pl.URL)
+			if err := p.startPlanPipeline(pat, identities[orgURL], pl, step); err != nil {
+				fmt.Fprintf(os.Stderr, "Error starting '%s': %s\n", pl.Name, err)
+				step.Status = "completed"
+				step.Result = "failed"
+				step.FinishedAt = time.Now()
+			}
+
+			// Save each queued build right away, so that a CLI killed before the
+			// next pipeline starts resumes following it instead of queuing it again
+			if err := savePlanState(statePath, steps); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+			}
+		}
+
+		if changed {
+			if err := savePlanState(statePath, steps); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+			}
+			fmt.Printf("\n[%s]\n", time.Now().Format("15:04:05"))
+			printPlanTable(plan, steps)
+		}
+
+		done := true
+		for _, pl := range plan.Pipelines {
+			if !steps[pl.Name].finished() {
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+
+		delay := time.Duration(interval) * time.Second
+		if checkFailed {
+			failures++
+			if failures > maxRetries {
+				fmt.Fprintf(os.Stderr, "\nError: Giving up after %d failed status checks in a row. Run the plan again to resume.\n", maxRetries)
+				os.Exit(runExitNoStatus)
+			}
+			delay = retryDelay(failures)
+			fmt.Printf("Status check failed (%d/%d). Retrying in %s...\n", failures, maxRetries, delay)
+		} else {
+			failures = 0
+		}
+		time.Sleep(delay)
+	}
+
+	// A finished plan has nothing to resume, the next run starts over
+	if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Warning: Could not remove plan state: %s\n", err)
+	}
+
+	p.printPlanSummary(plan, steps)
+}
+
+// startPlanPipeline queues the run of one plan pipeline and records it in step
+func (p *PiperunCmd) startPlanPipeline(pat string, identity *utils.Identity, pl planPipeline, step *planStep) error {
+	orgURL, project, definitionID, err := parseDefinitionURL(pl.URL)
+	if err != nil {
+		return err
+	}
+
+	opts := runOptions{
+		Branch:       pl.Branch,
+		StagesToSkip: pl.StagesToSkip,
+	}
+	if opts.Parameters, err = stringifyParams(pl.Parameters); err != nil {
+		return err
+	}
+	if opts.Variables, err = stringifyParams(pl.Variables); err != nil {
+		return err
+	}
+
+	build, err := triggerPipeline(pat, orgURL, project, definitionID, opts)
+	if err != nil {
+		return err
+	}
+
+	step.Status = "running"
+	step.BuildID = build.ID
+	step.URL = buildWebURL(orgURL, project, build.ID)
+	step.StartedAt = time.Now()
+	auditAction(identity, p.PAT != "", step.URL, "run pipeline "+definitionID)
+	return nil
+}
+
+// printPlanTable prints one line per plan pipeline with its status and build
+func printPlanTable(plan *releasePlan, steps map[string]*planStep) {
+	width := len("PIPELINE")
+	for _, pl := range plan.Pipelines {
+		if len(pl.Name) > width {
+			width = len(pl.Name)
+		}
+	}
+
+	fmt.Printf("  %-*s  %-9s  %-18s  %s\n", width, "PIPELINE", "STATUS", "RESULT", "BUILD")
+	for _, pl := range plan.Pipelines {
+		step := steps[pl.Name]
+		fmt.Printf("  %-*s  %-9s  %-18s  %s\n", width, pl.Name, step.Status, step.Result, step.URL)
+	}
+}
+
+// printPlanSummary prints the outcome of every pipeline and exits non-zero when
+// one of them did not succeed
+func (p *PiperunCmd) printPlanSummary(plan *releasePlan, steps map[string]*planStep) {
+	fmt.Println("\nSummary:")
+
+	failed := 0
+	for _, pl := range plan.Pipelines {
+		step := steps[pl.Name]
+		switch {
+		case step.Status == "skipped":
+			failed++
+			fmt.Printf("  - %s skipped (a dependency did not succeed)\n", pl.Name)
+		case step.Result == "succeeded":
+			fmt.Printf("  ✓ %s succeeded in %s\n", pl.Name, step.FinishedAt.Sub(step.StartedAt).Round(time.Second))
+		default:
+			failed++
+			fmt.Printf("  ✗ %s %s\n", pl.Name, step.Result)
+		}
+		if step.URL != "" {
+			fmt.Printf("      %s\n", step.URL)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d of %d pipelines did not succeed\n", failed, len(plan.Pipelines))
+		os.Exit(runExitFailed)
+	}
+	fmt.Printf("\n✓ All %d pipelines succeeded\n", len(plan.Pipelines))
+}
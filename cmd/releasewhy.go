@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"defenders-cli/internal/utils"
+)
+
+const releaseWhyHelp = `release why - Summarize why a pipeline run failed
+
+USAGE:
+  defenders release why <build-url> [flags]
+
+Collects the errors and warnings of the failed tasks, the last lines of their
+logs and the failing tests of the run into a short report.
+
+ARGUMENTS:
+  <build-url>  URL of the pipeline run (buildId URL)
+
+FLAGS:
+  --format <format>    text or markdown (default: text)
+  --lines <n>          Log lines to include per failed task (default: 20)
+  -o, --output <file>  Write the report to a file instead of the terminal
+  -t, --token          Personal Access Token (overrides config/env)
+
+EXAMPLES:
+  defenders release why https://dev.azure.com/org/proj/_build/results?buildId=123
+  defenders release why <build-url> --format markdown -o failure.md
+`
+
+// maxReportTests is the number of failing tests listed in a report
+const maxReportTests = 20
+
+// logTimestamp matches the timestamp ADO puts in front of every log line
+var logTimestamp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d+Z `)
+
+// failedTest is a failed test result of a run
+type failedTest struct {
+	Name  string
+	Error string
+}
+
+// failureSection is one failed task of a failure report
+type failureSection struct {
+	Path     string
+	Errors   []string
+	Warnings []string
+	LogTail  []string
+}
+
+func (p *PiperunCmd) explainFailure() {
+	if p.PipelineURL == "" {
+		fmt.Print(releaseWhyHelp)
+		os.Exit(1)
+	}
+
+	switch p.Format {
+	case "":
+		p.Format = "text"
+	case "text", "markdown":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown format '%s' (use text or markdown)\n", p.Format)
+		os.Exit(1)
+	}
+
+	lines := p.Lines
+	if lines <= 0 {
+		lines = 20
+	}
+
+	orgURL, project, buildID, err := parseBuildURL(p.PipelineURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing URL: %s\n", err)
+		os.Exit(1)
+	}
+
+	pat := utils.GetPAT(p.PAT)
+
+	build, err := getBuild(pat, orgURL, project, strconv.Itoa(buildID))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if build.Result == "succeeded" {
+		fmt.Printf("%s #%s succeeded, nothing to explain.\n", build.Definition.Name, build.BuildNumber)
+		return
+	}
+
+	records, err := getTimeline(pat, orgURL, project, buildID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not get timeline: %s\n", err)
+		os.Exit(1)
+	}
+
+	byID := make(map[string]*timelineRecord)
+	for i := range records {
+		byID[records[i].ID] = &records[i]
+	}
+
+	var failed []*timelineRecord
+	for i := range records {
+		r := &records[i]
+		if r.Type == "Task" && r.Result == "failed" {
+			failed = append(failed, r)
+		}
+	}
+	sortByStart(failed)
+
+	// The timeline's log references carry no line count, the build's log list does.
+	// Without it the tail falls back to reading the whole log.
+	lineCounts, err := buildLogLineCounts(pat, orgURL, project, buildID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not get log sizes: %s\n", err)
+	}
+
+	var sections []failureSection
+	for _, task := range failed {
+		section := failureSection{Path: recordPath(byID, task)}
+		for _, issue := range task.Issues {
+			switch issue.Type {
+			case "error":
+				section.Errors = append(section.Errors, issue.Message)
+			case "warning":
+				section.Warnings = append(section.Warnings, issue.Message)
+			}
+		}
+		if task.Log != nil {
+			// Read twice the wanted lines from the end, blank lines are dropped
+			start := lineCounts[task.Log.ID] - 2*lines + 1
+			if start < 1 {
+				start = 1
+			}
+			text, err := getBuildLog(pat, orgURL, project, buildID, task.Log.ID, start)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Could not get log of '%s': %s\n", task.Name, err)
+			} else {
+				section.LogTail = logTail(text, lines)
+			}
+		}
+		sections = append(sections, section)
+	}
+
+	tests, err := failedTests(pat, orgURL, project, buildID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not get test results: %s\n", err)
+	}
+
+	report := failureReport(build, buildWebURL(orgURL, project, buildID), sections, tests, p.Format == "markdown")
+
+	if p.Output != "" {
+		if err := os.WriteFile(p.Output, []byte(report), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not write report: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Report written to %s\n", p.Output)
+		return
+	}
+	fmt.Print(report)
+}
+
+// recordPath returns "Stage / Job / Task" for a timeline record, without phases
+func recordPath(byID map[string]*timelineRecord, r *timelineRecord) string {
+	var parts []string
+	for ; r != nil; r = byID[r.ParentID] {
+		if r.Type != "Phase" {
+			parts = append([]string{r.Name}, parts...)
+		}
+		if r.ParentID == "" {
+			break
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// logTail returns the last n non-empty lines of a log without their timestamps
+func logTail(text string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(logTimestamp.ReplaceAllString(line, ""), "\r")
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// buildLogLineCounts returns the number of lines of every log of a build by log ID
+func buildLogLineCounts(pat, orgURL, project string, buildID int) (map[int]int, error) {
+	var data struct {
+		Value []struct {
+			ID        int `json:"id"`
+			LineCount int `json:"lineCount"`
+		} `json:"value"`
+	}
+	reqURL := fmt.Sprintf("%s/%s/_apis/build/builds/%d/logs?api-version=7.1", orgURL, url.PathEscape(project), buildID)
+	if err := utils.ADORequest(pat, "GET", reqURL, nil, &data); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int)
+	for _, l := range data.Value {
+		counts[l.ID] = l.LineCount
+	}
+	return counts, nil
+}
+
+// testResultsPageSize is the number of test results requested per call
+const testResultsPageSize = 100
+
+// failedTests returns the failed test results of all test runs of a build
+func failedTests(pat, orgURL, project string, buildID int) ([]failedTest, error) {
+	base := fmt.Sprintf("%s/%s/_apis/test", orgURL, url.PathEscape(project))
+
+	var runs struct {
+		Value []struct {
+			ID int `json:"id"`
+		} `json:"value"`
+	}
+	buildURI := fmt.Sprintf("vstfs:///Build/Build/%d", buildID)
+	reqURL := fmt.Sprintf("%s/runs?buildUri=%s&api-version=7.1", base, url.QueryEscape(buildURI))
+	if err := utils.ADORequest(pat, "GET", reqURL, nil, &runs); err != nil {
+		return nil, err
+	}
+
+	var tests []failedTest
+	for _, run := range runs.Value {
+		// Results are paged, every page is read so that the count is complete
+		for skip := 0; ; skip += testResultsPageSize {
+			var results struct {
+				Value []struct {
+					TestCaseTitle     string `json:"testCaseTitle"`
+					AutomatedTestName string `json:"automatedTestName"`
+					ErrorMessage      string `json:"errorMessage"`
+				} `json:"value"`
+			}
+			reqURL := fmt.Sprintf("%s/Runs/%d/results?outcomes=Failed&$top=%d&$skip=%d&api-version=7.1",
+				base, run.ID, testResultsPageSize, skip)
+			if err := utils.ADORequest(pat, "GET", reqURL, nil, &results); err != nil {
+				return tests, err
+			}
+			for _, r := range results.Value {
+				name := r.AutomatedTestName
+				if name == "" {
+					name = r.TestCaseTitle
+				}
+				tests = append(tests, failedTest{Name: name, Error: r.ErrorMessage})
+			}
+			if len(results.Value) < testResultsPageSize {
+				break
+			}
+		}
+	}
+	return tests, nil
+}
+
+// failureReport renders a failure report as plain text or Markdown
+func failureReport(build *buildRun, buildURL string, sections []failureSection, tests []failedTest, markdown bool) string {
+	var b strings.Builder
+
+	title := fmt.Sprintf("%s #%s %s", build.Definition.Name, build.BuildNumber, build.Result)
+	if build.Result == "" {
+		title = fmt.Sprintf("%s #%s (%s)", build.Definition.Name, build.BuildNumber, build.Status)
+	}
+	branch := branchName(build.SourceBranch)
+	commit := build.SourceVersion
+	if len(commit) > 8 {
+		commit = commit[:8]
+	}
+
+	if markdown {
+		fmt.Fprintf(&b, "## %s\n\n", title)
+		fmt.Fprintf(&b, "- Run: %s\n- Branch: `%s` at `%s`\n", buildURL, branch, commit)
+	} else {
+		fmt.Fprintf(&b, "%s\n", title)
+		fmt.Fprintf(&b, "  Run:    %s\n  Branch: %s at %s\n", buildURL, branch, commit)
+	}
+
+	if len(sections) == 0 {
+		b.WriteString("\nNo failed tasks were reported.\n")
+	}
+	for _, s := range sections {
+		if markdown {
+			fmt.Fprintf(&b, "\n### ✗ %s\n\n", s.Path)
+			for _, e := range s.Errors {
+				fmt.Fprintf(&b, "- **error:** %s\n", firstLine(e))
+			}
+			for _, w := range s.Warnings {
+				fmt.Fprintf(&b, "- warning: %s\n", firstLine(w))
+			}
+			if len(s.LogTail) > 0 {
+				fmt.Fprintf(&b, "\n```text\n%s\n```\n", strings.Join(s.LogTail, "\n"))
+			}
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n✗ %s\n", s.Path)
+		for _, e := range s.Errors {
+			fmt.Fprintf(&b, "  error:   %s\n", firstLine(e))
+		}
+		for _, w := range s.Warnings {
+			fmt.Fprintf(&b, "  warning: %s\n", firstLine(w))
+		}
+		if len(s.LogTail) > 0 {
+			b.WriteString("  Log:\n")
+			for _, line := range s.LogTail {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+		}
+	}
+
+	if len(tests) > 0 {
+		if markdown {
+			fmt.Fprintf(&b, "\n### Failing tests (%d)\n\n", len(tests))
+		} else {
+			fmt.Fprintf(&b, "\nFailing tests (%d):\n", len(tests))
+		}
+		for i, t := range tests {
+			if i == maxReportTests {
+				more := fmt.Sprintf("... and %d more", len(tests)-maxReportTests)
+				if markdown {
+					fmt.Fprintf(&b, "- %s\n", more)
+				} else {
+					fmt.Fprintf(&b, "  %s\n", more)
+				}
+				break
+			}
+			switch {
+			case markdown && t.Error != "":
+				fmt.Fprintf(&b, "- `%s`: %s\n", t.Name, firstLine(t.Error))
+			case markdown:
+				fmt.Fprintf(&b, "- `%s`\n", t.Name)
+			case t.Error != "":
+				fmt.Fprintf(&b, "  %s\n      %s\n", t.Name, firstLine(t.Error))
+			default:
+				fmt.Fprintf(&b, "  %s\n", t.Name)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// firstLine returns the first non-empty line of a message
+func firstLine(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"defenders-cli/internal/utils"
+)
+
+const stackHelp = `stack - Open and maintain a chain of stacked PRs
+
+USAGE:
+  defenders stack [branch...] [flags]
+
+ARGUMENTS:
+  [branch...]  Branches of the stack, bottom first. The first branch targets
+               the base branch, every other branch targets the one below it.
+               When omitted, the chain from the previous run in this
+               repository is used.
+
+FLAGS:
+  -b, --target     Base branch for the bottom of the stack (default: develop/main/master)
+  -i, --work-item  Work item ID to link to newly created PRs
+  -t, --token      Personal Access Token (overrides config/env)
+  -f, --force      Push branches without asking
+  -h, --help       Show this help message
+
+BEHAVIOUR:
+  - Branches without a PR are pushed if needed and get one, titled after the branch name
+  - Active PRs whose target does not match the chain are retargeted
+  - When a lower PR has completed, the next PR is retargeted onto the
+    branch the completed PR was merged into
+
+EXAMPLES:
+  defenders stack feature/api feature/ui feature/docs
+  defenders stack                      # Re-sync the saved chain
+  defenders stack feature/a feature/b -b release/2024.10
+`
+
+type StackCmd struct {
+	Branches []string
+	Target   string
+	WorkItem string
+	PAT      string
+}
+
+func (s *StackCmd) Run() {
+	branches := s.Branches
+	if len(branches) == 0 {
+		branches = utils.GetStackBranches()
+	}
+	if len(branches) == 0 {
+		fmt.Print(stackHelp)
+		os.Exit(1)
+	}
+
+	if err := utils.SaveStackBranches(branches); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+	}
+
+	pat := utils.GetPAT(s.PAT)
+
+	base := s.Target
+	if base == "" {
+		defaultBranch, err := utils.GetDefaultBranch()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		base = defaultBranch
+	}
+
+	fmt.Printf("Stack: %s -> %s\n\n", base, strings.Join(branches, " -> "))
+
+	// target is the branch the next PR in the chain should merge into
+	target := base
+	for _, branch := range branches {
+		pr, err := findLatestPR(pat, branch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		switch {
+		case pr != nil && pr.Status == "completed":
+			// The branch is merged, so the next PR moves down to this PR's target
+			fmt.Printf("  %s: PR #%d completed\n", branch, pr.PullRequestID)
+			continue
+
+		case pr != nil && pr.Status == "active":
+			current := branchName(pr.TargetRefName)
+			if current != target {
+				if err := retargetPR(pat, pr, target); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: Failed to retarget PR #%d: %s\n", pr.PullRequestID, err)
+					os.Exit(1)
+				}
+				fmt.Printf("  %s: PR #%d retargeted %s -> %s\n", branch, pr.PullRequestID, current, target)
+			} else {
+				fmt.Printf("  %s: PR #%d up to date (-> %s)\n", branch, pr.PullRequestID, target)
+			}
+			fmt.Printf("    %s\n", prWebURL(pr))
+
+		default:
+			if err := ensureBranchPushed(branch); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			created, err := createPR(pat, branch, target, utils.GetBranchTitle(branch), s.WorkItem)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("  %s: PR #%d created (-> %s)\n", branch, created.PullRequestID, target)
+			fmt.Printf("    %s\n", prWebURL(created))
+		}
+
+		target = branch
+	}
+}
+
+// ParseStackArgs parses command line arguments for stack command
+func ParseStackArgs(args []string) *StackCmd {
+	cmd := &StackCmd{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-b" || arg == "--target":
+			if i+1 < len(args) {
+				i++
+				cmd.Target = args[i]
+			}
+		case arg == "-i" || arg == "--work-item":
+			if i+1 < len(args) {
+				i++
+				cmd.WorkItem = args[i]
+			}
+		case arg == "-t" || arg == "--token":
+			if i+1 < len(args) {
+				i++
+				cmd.PAT = args[i]
+			}
+		case strings.HasPrefix(arg, "--target="):
+			cmd.Target = strings.TrimPrefix(arg, "--target=")
+		case strings.HasPrefix(arg, "--work-item="):
+			cmd.WorkItem = strings.TrimPrefix(arg, "--work-item=")
+		case strings.HasPrefix(arg, "--token="):
+			cmd.PAT = strings.TrimPrefix(arg, "--token=")
+		case arg == "-f" || arg == "--force":
+			utils.Force = true
+		case arg == "-h" || arg == "--help":
+			fmt.Print(stackHelp)
+			os.Exit(0)
+		default:
+			if !strings.HasPrefix(arg, "-") {
+				cmd.Branches = append(cmd.Branches, arg)
+			}
+		}
+	}
+
+	return cmd
+}
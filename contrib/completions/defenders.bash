@@ -8,7 +8,7 @@ _defenders_completions() {
     prev="${COMP_WORDS[COMP_CWORD-1]}"
 
     # Main commands
-    local commands="conf get-token cado prme release pr help"
+    local commands="conf get-token cado prme stack release pr help"
     
     # Help flags
     local help_flags="-h --help"
@@ -9,6 +9,7 @@ complete -c defenders -n "test (count (commandline -opc)) -eq 1" -a conf -d "Con
 complete -c defenders -n "test (count (commandline -opc)) -eq 1" -a get-token -d "Get authentication token"
 complete -c defenders -n "test (count (commandline -opc)) -eq 1" -a cado -d "Create ADO Feature work item"
 complete -c defenders -n "test (count (commandline -opc)) -eq 1" -a prme -d "Create PR from current branch"
+complete -c defenders -n "test (count (commandline -opc)) -eq 1" -a stack -d "Open and retarget stacked PRs"
 complete -c defenders -n "test (count (commandline -opc)) -eq 1" -a release -d "Pipeline runner"
 complete -c defenders -n "test (count (commandline -opc)) -eq 1" -a pr -d "Pull request actions"
 complete -c defenders -n "test (count (commandline -opc)) -eq 1" -a help -d "Show help information"
@@ -32,17 +33,57 @@ complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (comma
 # prme subcommand options
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'prme'" -s i -l work-item -d "Work item ID to link to the PR" -r
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'prme'" -s t -l title -d "Custom PR title" -r
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'prme'" -s b -l target -d "Target branch" -r
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'prme'" -s f -l force -d "Push without asking"
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'prme'" -s h -l help -d "Show help"
 
-# release subcommand - has subcommands: run, monitor-trigger
+# stack subcommand options
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'stack'" -s b -l target -d "Base branch for the bottom of the stack" -r
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'stack'" -s i -l work-item -d "Work item ID to link to new PRs" -r
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'stack'" -s t -l token -d "Personal Access Token" -r
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'stack'" -s f -l force -d "Push branches without asking"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'stack'" -s h -l help -d "Show help"
+
+# release subcommand - has subcommands: run, monitor-trigger, monitor-status, logs, why, artifacts, cancel, retry, rerun, approve, plan, list, runs
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a run -d "Run a pipeline directly"
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a monitor-trigger -d "Monitor pipeline and trigger another"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a monitor-status -d "Show background monitors"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a logs -d "Show run timeline and logs"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a why -d "Summarize why a run failed"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a artifacts -d "List and download run artifacts"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a cancel -d "Cancel a run"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a retry -d "Rerun failed jobs of a run"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a rerun -d "Start a new run like an earlier one"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a approve -d "Approve or reject pending approvals"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a plan -d "Run a YAML release plan"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a list -d "List pipeline definitions"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -a runs -d "List recent runs of a pipeline"
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -s t -l token -d "Personal Access Token" -r
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -s i -l interval -d "Check interval in seconds" -r
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'release'" -s h -l help -d "Show help"
 
 # pr subcommand options
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a update -d "Update an existing PR"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a complete -d "Complete a PR"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a abandon -d "Abandon a PR"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a reactivate -d "Reactivate an abandoned PR"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a list -d "List and filter PRs"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a comments -d "List PR comment threads"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a comment -d "Add a PR comment"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a reply -d "Reply to a comment thread"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a resolve -d "Resolve a comment thread"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a diff -d "Show the PR diff locally"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a checkout -d "Check out the PR source branch"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a status -d "Show policy and build status"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a wait -d "Wait for a PR to be approved or completed"
+complete -c defenders -n "test (count (commandline -opc)) -eq 2; and test (commandline -opc)[2] = 'pr'" -a requeue -d "Re-queue expired or failed validation builds"
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -l approve -d "Approve the Pull Request"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -l approve-with-suggestions -d "Approve with suggestions"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -l wait-for-author -d "Wait for author"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -l reject -d "Reject the Pull Request"
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -l reset -d "Reset your vote on the PR"
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -s m -l comment -d "Comment text, or the comment posted with a vote" -r
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -s p -l parallel -d "PRs voted on concurrently" -r
+complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -l passing-builds -d "Only PRs whose builds passed"
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -s t -l token -d "Personal Access Token" -r
 complete -c defenders -n "test (count (commandline -opc)) -ge 2; and test (commandline -opc)[2] = 'pr'" -s h -l help -d "Show help"
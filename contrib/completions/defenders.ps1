@@ -8,6 +8,7 @@ Register-ArgumentCompleter -Native -CommandName defenders -ScriptBlock {
         'get-token' = 'Get authentication token'
         'cado' = 'Azure DevOps operations'
         'prme' = 'Create pull request'
+        'stack' = 'Stacked pull requests'
         'release' = 'Run pipeline/release'
         'pr' = 'Pull request handler'
         'help' = 'Show help information'
@@ -0,0 +1,325 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// adoResourceID is the Azure DevOps application ID used to request az login tokens
+const adoResourceID = "499b84ac-1321-427f-aa17-267ca6975798"
+
+var adoHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+// cachedAccessToken holds the az login token so it is only requested once per run
+var cachedAccessToken string
+
+// ADOAuthHeader returns the Authorization header value for Azure DevOps REST calls
+// If pat is empty, an access token for the az login identity is used
+func ADOAuthHeader(pat string) (string, error) {
+	if pat != "" {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+pat)), nil
+	}
+
+	if cachedAccessToken == "" {
+		stdout, stderr, err := RunCommand("az", "account", "get-access-token",
+			"--resource", adoResourceID,
+			"--query", "accessToken",
+			"-o", "tsv",
+		)
+		if err != nil {
+			return "", fmt.Errorf("could not get az login access token: %s", strings.TrimSpace(stderr))
+		}
+		cachedAccessToken = strings.TrimSpace(stdout)
+	}
+
+	return "Bearer " + cachedAccessToken, nil
+}
+
+// ADORequest calls the Azure DevOps REST API directly
+// This is used for operations the az CLI does not expose. body and out may be nil.
+func ADORequest(pat, method, reqURL string, body any, out any) error {
+	data, err := adoDo(pat, method, reqURL, body, "application/json")
+	if err != nil {
+		return err
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("could not parse response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ADOGetText fetches a plain text resource of the Azure DevOps REST API, such as a build log
+func ADOGetText(pat, reqURL string) (string, error) {
+	data, err := adoDo(pat, "GET", reqURL, nil, "text/plain")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// adoDo sends an authenticated request and returns the response body
+func adoDo(pat, method, reqURL string, body any, accept string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("could not serialize request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, reqURL, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := ADOAuthHeader(pat)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Accept", accept)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := adoHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		// ADO error responses carry a JSON body with a "message" field
+		var adoErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &adoErr) == nil && adoErr.Message != "" {
+			return nil, fmt.Errorf("%s (HTTP %d)", adoErr.Message, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%s %s returned HTTP %d", method, reqURL, resp.StatusCode)
+	}
+
+	return data, nil
+}
+
+// ResolveADOUrl splits an Azure DevOps URL into the organization URL, the path
+// segments that follow the organization, and the query parameters.
+// Supports URLs like:
+// - https://dev.azure.com/{org}/{project}/...
+// - https://{org}.visualstudio.com/{project}/...
+func ResolveADOUrl(rawURL string) (orgURL string, pathParts []string, queryParams url.Values, err error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	pathParts = strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(pathParts) == 1 && pathParts[0] == "" {
+		pathParts = nil
+	}
+
+	switch {
+	case strings.Contains(parsed.Host, "dev.azure.com"):
+		// Format: https://dev.azure.com/{org}/... - org is the first path segment
+		if len(pathParts) < 1 {
+			return "", nil, nil, fmt.Errorf("invalid ADO URL - expected org in path")
+		}
+		orgURL = fmt.Sprintf("https://dev.azure.com/%s", pathParts[0])
+		pathParts = pathParts[1:]
+	case strings.Contains(parsed.Host, "visualstudio.com"):
+		// Format: https://{org}.visualstudio.com/... - org is part of hostname
+		orgURL = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+	default:
+		return "", nil, nil, fmt.Errorf("unrecognized ADO URL format")
+	}
+
+	return orgURL, pathParts, parsed.Query(), nil
+}
+
+// Identity is the Azure DevOps user behind a set of credentials
+type Identity struct {
+	ID          string
+	DisplayName string
+	Email       string
+}
+
+// GetIdentity returns the user authenticated by pat (or by az login when pat is empty)
+func GetIdentity(pat, orgURL string) (*Identity, error) {
+	var data struct {
+		AuthenticatedUser struct {
+			ID                  string `json:"id"`
+			ProviderDisplayName string `json:"providerDisplayName"`
+			Properties          struct {
+				Account struct {
+					Value string `json:"$value"`
+				} `json:"Account"`
+			} `json:"properties"`
+		} `json:"authenticatedUser"`
+	}
+
+	if err := ADORequest(pat, "GET", orgURL+"/_apis/connectionData", nil, &data); err != nil {
+		return nil, fmt.Errorf("could not resolve identity: %w", err)
+	}
+
+	user := data.AuthenticatedUser
+	return &Identity{
+		ID:          user.ID,
+		DisplayName: user.ProviderDisplayName,
+		Email:       user.Properties.Account.Value,
+	}, nil
+}
+
+// ConfirmIdentity resolves the user that will act with the PAT given on the
+// command line (or the configured credentials when flagPAT is empty).
+// When a PAT is supplied and belongs to someone other than the configured user,
+// the user has to confirm before the caller acts on their behalf.
+// Without a PAT there is nothing to confirm: the identity is only resolved for
+// the audit log, and is nil with a warning when that fails.
+func ConfirmIdentity(flagPAT, orgURL string) (*Identity, error) {
+	if flagPAT == "" {
+		acting, err := GetIdentity(GetPAT(""), orgURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+			return nil, nil
+		}
+		return acting, nil
+	}
+
+	acting, err := GetIdentity(GetPAT(flagPAT), orgURL)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Acting as: %s <%s>\n", acting.DisplayName, acting.Email)
+
+	configured, err := GetIdentity(GetPAT(""), orgURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not resolve the configured user: %s\n", err)
+		if !AskUser("Continue as %s? [y/N]: ", acting.DisplayName) {
+			return nil, fmt.Errorf("cancelled")
+		}
+		return acting, nil
+	}
+
+	if configured.ID != acting.ID {
+		if !AskUser("The supplied PAT belongs to %s, not to you (%s). Continue on their behalf? [y/N]: ",
+			acting.DisplayName, configured.DisplayName) {
+			return nil, fmt.Errorf("cancelled")
+		}
+	}
+	return acting, nil
+}
+
+// adoDownloadClient has no overall timeout, artifact downloads can take long
+var adoDownloadClient = &http.Client{}
+
+// ADODownload downloads reqURL to path. When path already holds part of the
+// file from an interrupted download, only the rest is requested. progress, when
+// not nil, is called with the bytes written so far and the total size (0 if unknown).
+func ADODownload(pat, reqURL, path string, progress func(written, total int64)) error {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return err
+	}
+	auth, err := ADOAuthHeader(pat)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", auth)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := adoDownloadClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The file is only complete when the server reports the same size
+		// ("bytes */<size>"), otherwise it is not a part of this file
+		var size int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes */%d", &size); err == nil && size == offset {
+			return nil
+		}
+		resp.Body.Close()
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		return ADODownload(pat, reqURL, path, progress)
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range, start over
+		flags |= os.O_TRUNC
+		offset = 0
+	case resp.StatusCode == http.StatusNonAuthoritativeInfo:
+		// ADO answers an expired or invalid PAT with a sign-in page
+		return fmt.Errorf("GET %s was redirected to a sign-in page (HTTP 203) - check that the PAT is valid and not expired", reqURL)
+	default:
+		return fmt.Errorf("GET %s returned HTTP %d", reqURL, resp.StatusCode)
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	total := int64(0)
+	if resp.ContentLength > 0 {
+		total = offset + resp.ContentLength
+	}
+
+	written := offset
+	buf := make([]byte, 256*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := f.Write(buf[:n]); err != nil {
+				return err
+			}
+			written += int64(n)
+			if progress != nil {
+				progress(written, total)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	if total > 0 && written != total {
+		return fmt.Errorf("download incomplete: %d of %d bytes", written, total)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditEntry is one line of the local audit log
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Identity  string    `json:"identity"`
+	Email     string    `json:"email"`
+	Delegated bool      `json:"delegated"`
+	Target    string    `json:"target"`
+	Action    string    `json:"action"`
+}
+
+// GetAuditLogPath returns the full path to the audit log
+func GetAuditLogPath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "audit.log"), nil
+}
+
+// WriteAuditLog appends an entry to the audit log, one JSON object per line.
+// The file is only ever opened for appending, existing entries are never rewritten.
+func WriteAuditLog(entry AuditEntry) error {
+	logPath, err := GetAuditLogPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("could not create config directory: %w", err)
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not serialize audit entry: %w", err)
+	}
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not write audit log: %w", err)
+	}
+	return nil
+}
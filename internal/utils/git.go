@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -56,3 +57,118 @@ func GetBranchTitle(branch string) string {
 	parts := strings.Split(branch, "/")
 	return parts[len(parts)-1]
 }
+
+// FindStackParent returns the nearest remote branch the current branch was stacked on
+// It walks first-parent history between HEAD and its merge base with the default branch
+// and returns the first other branch on origin pointing at one of those commits.
+// Returns an empty string when the branch sits directly on the default branch.
+func FindStackParent(branch, defaultBranch string) (string, error) {
+	stdout, _, err := RunCommand("git", "merge-base", "HEAD", "origin/"+defaultBranch)
+	if err != nil {
+		return "", fmt.Errorf("could not find merge base with origin/%s", defaultBranch)
+	}
+	base := strings.TrimSpace(stdout)
+
+	stdout, _, err = RunCommand("git", "log", "--first-parent", "--format=%D",
+		"--decorate-refs=refs/remotes/origin/", base+"..HEAD")
+	if err != nil {
+		return "", fmt.Errorf("could not read branch history")
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		for _, ref := range strings.Split(line, ",") {
+			ref = strings.TrimSpace(ref)
+			if !strings.HasPrefix(ref, "origin/") {
+				continue
+			}
+			name := strings.TrimPrefix(ref, "origin/")
+			if name == "HEAD" || name == branch || name == defaultBranch {
+				continue
+			}
+			return name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// GetStackBranches returns the stacked branch chain saved in the local git config
+func GetStackBranches() []string {
+	stdout, _, err := RunCommand("git", "config", "--get-all", "defenders.stack")
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(stdout)
+}
+
+// SaveStackBranches stores the stacked branch chain in the local git config
+func SaveStackBranches(branches []string) error {
+	RunCommand("git", "config", "--unset-all", "defenders.stack")
+	for _, branch := range branches {
+		_, stderr, err := RunCommand("git", "config", "--add", "defenders.stack", branch)
+		if err != nil {
+			return fmt.Errorf("could not save stack: %s", strings.TrimSpace(stderr))
+		}
+	}
+	return nil
+}
+
+// HasUncommittedChanges reports whether the working tree has staged, unstaged or untracked changes
+func HasUncommittedChanges() (bool, error) {
+	stdout, _, err := RunCommand("git", "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("could not read git status")
+	}
+	return strings.TrimSpace(stdout) != "", nil
+}
+
+// GetUpstream returns the upstream of a local branch (e.g. origin/feature/x), or empty if none is set
+func GetUpstream(branch string) string {
+	stdout, _, err := RunCommand("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", branch+"@{u}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(stdout)
+}
+
+// CountCommits returns the number of commits reachable from to but not from from
+func CountCommits(from, to string) (int, error) {
+	stdout, _, err := RunCommand("git", "rev-list", "--count", from+".."+to)
+	if err != nil {
+		return 0, fmt.Errorf("could not compare %s with %s", from, to)
+	}
+	return strconv.Atoi(strings.TrimSpace(stdout))
+}
+
+// PushBranch pushes a local branch to origin and sets it as upstream
+func PushBranch(branch string) error {
+	return RunCommandWithOutput("git", "push", "-u", "origin", branch)
+}
+
+// GetRemoteURL returns the fetch URL of the origin remote
+func GetRemoteURL() (string, error) {
+	stdout, _, err := RunCommand("git", "remote", "get-url", "origin")
+	if err != nil {
+		return "", fmt.Errorf("no origin remote found")
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+// FetchBranches fetches branches from origin, updating their origin/* refs
+func FetchBranches(branches ...string) error {
+	args := []string{"fetch", "origin"}
+	for _, branch := range branches {
+		args = append(args, fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch))
+	}
+	_, stderr, err := RunCommand("git", args...)
+	if err != nil {
+		return fmt.Errorf("could not fetch from origin: %s", strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// LocalBranchExists reports whether a local branch with the given name exists
+func LocalBranchExists(branch string) bool {
+	_, _, err := RunCommand("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
@@ -3,15 +3,21 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 )
 
 // Force disables interactive prompts when true
 var Force bool
 
+// promptInput is where AskUser reads answers from
+var promptInput io.Reader = os.Stdin
+
 const HELPER = `Hi There!
 This CLI for Azure DevOps operations
 
@@ -23,8 +29,9 @@ COMMANDS:
   get-token   Open browser to create PAT with required permissions
   cado        Create ADO Feature work item with parent link
   prme        Create PR from current branch to default branch
-  release     Pipeline operations (run, monitor-trigger)
-  pr          PR approval operations (approve, reset)
+  stack       Open and retarget a chain of stacked PRs
+  release     Pipeline operations (list, runs, run, logs, why, approve, ...)
+  pr          PR operations (vote, list, update, complete, comments, wait)
 
 GLOBAL FLAGS:
   -h, --help  Show this help message
@@ -36,10 +43,23 @@ EXAMPLES:
   defenders cado --title "My Feature" --parent 12345
   defenders prme
   defenders prme -i 12345 -t "My PR Title"
+  defenders prme -b release/2024.10
+  defenders stack feature/api feature/ui
   defenders release run <pipeline-url>
   defenders release monitor-trigger <wait-url> <trigger-url>
+  defenders release plan run release.yaml
   defenders pr --approve <pr-url>
   defenders pr --reset <pr-url>
+  defenders pr --approve <pr-url> <pr-url> ...
+  defenders pr --wait-for-author <pr-url> -m "Please add tests"
+  defenders pr update <pr-url> --title "New title"
+  defenders pr complete <pr-url> --merge-strategy squash
+  defenders pr list --waiting-on-me
+  defenders pr comments <pr-url>
+  defenders pr diff <pr-url> --name-only
+  defenders pr status <pr-url>
+  defenders pr wait <pr-url> --until approved --timeout 2h
+  defenders pr requeue <pr-url> --wait
 
 AUTHENTICATION:
   Run 'defenders conf' to set up your configuration.
@@ -54,13 +74,28 @@ func AskUser(message string, args ...any) bool {
 	}
 
 	fmt.Printf(message, args...)
-	reader := bufio.NewReader(os.Stdin)
+	reader := bufio.NewReader(promptInput)
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(strings.ToLower(input))
 
 	return input == "y" || input == "yes"
 }
 
+// PromptFromTerminal makes AskUser read answers from the terminal instead of
+// stdin, for commands that read their input from stdin
+func PromptFromTerminal() error {
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+	tty, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("no terminal to ask for confirmation: %w", err)
+	}
+	promptInput = tty
+	return nil
+}
+
 // RunCommand executes a shell command and returns stdout, stderr, and error
 func RunCommand(name string, args ...string) (string, string, error) {
 	cmd := exec.Command(name, args...)
@@ -115,6 +150,31 @@ func RunCommandWithOptionalPAT(pat string, name string, args ...string) (string,
 	return RunCommand(name, args...)
 }
 
+// RunParallel calls fn for every index in [0, count) using at most workers goroutines
+func RunParallel(workers, count int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < count; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
 // GetEnvOrDefault returns environment variable value or default if not set
 func GetEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -10,7 +10,7 @@ import (
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Println(utils.HELPER)
+		fmt.Print(utils.HELPER)
 		os.Exit(0)
 	}
 
@@ -19,7 +19,7 @@ func main() {
 
 	// Check for global help flag
 	if command == "-h" || command == "--help" || command == "help" {
-		fmt.Println(utils.HELPER)
+		fmt.Print(utils.HELPER)
 		os.Exit(0)
 	}
 
@@ -40,6 +40,10 @@ func main() {
 		prmeCmd := cmd.ParsePrmeArgs(args)
 		prmeCmd.Run()
 
+	case "stack":
+		stackCmd := cmd.ParseStackArgs(args)
+		stackCmd.Run()
+
 	case "release":
 		releaseCmd := cmd.ParsePiperunArgs(args)
 		releaseCmd.Run()